@@ -6,48 +6,18 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"log"
 	"math"
 	"os"
-	"path"
-	"strings"
+	"slices"
 	"unicode/utf8"
-
-	"github.com/eljamo/libpass/v8/config/option"
 )
 
-//go:embed preset/* word_list/*
+//go:embed blocklist/* pin/* preset/* word_list/*
 var files embed.FS
 
-var fileMap = map[string]map[string]string{
-	option.ConfigKeyPreset: {
-		option.PresetAppleID:       "appleid.json",
-		option.PresetDefault:       "default.json",
-		option.PresetNTLM:          "ntlm.json",
-		option.PresetSecurityQ:     "securityq.json",
-		option.PresetWeb16:         "web16.json",
-		option.PresetWeb16XKPasswd: "web16_xkpasswd.json",
-		option.PresetWeb32:         "web32.json",
-		option.PresetWiFi:          "wifi.json",
-		option.PresetXKCD:          "xkcd.json",
-		option.PresetXKCDXKPasswd:  "xkcd_xkpasswd.json",
-	},
-	option.ConfigKeyWordList: {
-		option.WordList40k:           "40k.txt",
-		option.WordListAll:           "all.txt",
-		option.WordListDoctorWho:     "doctor_who.txt",
-		option.WordListEN:            "en.txt",
-		option.WordListENSmall:       "en_small.txt",
-		option.WordListGameOfThrones: "game_of_thrones.txt",
-		option.WordListHarryPotter:   "harry_potter.txt",
-		option.WordListMiddleEarth:   "middle_earth.txt",
-		option.WordListPokemon:       "pokemon.txt",
-		option.WordListStarTrek:      "star_trek.txt",
-		option.WordListStarWars:      "star_wars.txt",
-		option.WordListSunborn:       "sunborn.txt",
-	},
-}
-
 var (
 	ErrReadFile        = errors.New("failed to read file")
 	ErrJSON            = errors.New("invalid JSON content")
@@ -55,12 +25,6 @@ var (
 	ErrInvalidPreset   = errors.New("invalid preset")
 )
 
-func keyToFile(key, fileType string) (string, bool) {
-	file, ok := fileMap[fileType][strings.ToUpper(key)]
-
-	return file, ok
-}
-
 func loadJSONFileData(filePath string, readerFunc func(string) ([]byte, error)) (map[string]any, error) {
 	data, err := readerFunc(filePath)
 	if err != nil {
@@ -82,33 +46,25 @@ func LoadJSONFile(filePath string) (map[string]any, error) {
 	return loadJSONFileData(filePath, os.ReadFile)
 }
 
-func getWordListFilePath(key string) (string, error) {
-	fileName, ok := keyToFile(key, option.ConfigKeyWordList)
-	if !ok {
-		return "", errors.Join(ErrInvalidWordList, fmt.Errorf("invalid %s value (%s)", option.ConfigKeyWordList, key))
-	}
-
-	return path.Join(option.ConfigKeyWordList, fileName), nil
-}
-
-// GetWordList retrieves a list of words from an embedded file identified by the
-// given key. The method returns the file's non-empty lines as a slice of
-// strings, with any carriage returns from CRLF endings stripped. If the file
-// cannot be found or read, an error is returned.
+// GetWordList retrieves a list of words from the word list registered in
+// WordLists under the given key. The method returns the list's non-empty lines
+// as a slice of strings, with any carriage returns from CRLF endings stripped.
+// If the word list cannot be found or read, an error is returned.
 func GetWordList(key string) ([]string, error) {
-	filePath, err := getWordListFilePath(key)
+	wi, err := WordLists.index(key)
 	if err != nil {
 		return nil, err
 	}
 
-	return readAndFilterWords(filePath, 1, math.MaxInt, files)
+	return slices.Clone(wi.words), nil
 }
 
-// readAndFilterWords reads from an io.Reader, and filters the words based on the specified minimum and maximum length, measured in runes.
-func readAndFilterWords(filePath string, minLen int, maxLen int, fs embed.FS) ([]string, error) {
-	file, err := fs.Open(filePath)
+// readAndFilterWords opens a file from the given file system, and filters the
+// words based on the specified minimum and maximum length, measured in runes.
+func readAndFilterWords(filePath string, minLen int, maxLen int, fsys fs.FS) ([]string, error) {
+	file, err := fsys.Open(filePath)
 	if err != nil {
-		return nil, errors.Join(ErrReadFile, fmt.Errorf("failed to open embedded text file (%s): %w", filePath, err))
+		return nil, errors.Join(ErrReadFile, fmt.Errorf("failed to open text file (%s): %w", filePath, err))
 	}
 	defer func() {
 		if err := file.Close(); err != nil {
@@ -116,10 +72,26 @@ func readAndFilterWords(filePath string, minLen int, maxLen int, fs embed.FS) ([
 		}
 	}()
 
+	wl, err := filterWords(file, minLen, maxLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan text file (%s): %w", filePath, err)
+	}
+
+	return wl, nil
+}
+
+// filterWords reads one word per line from an io.Reader, and filters the words
+// based on the specified minimum and maximum length, measured in runes.
+// Carriage returns from CRLF endings are stripped and empty lines are skipped.
+func filterWords(r io.Reader, minLen int, maxLen int) ([]string, error) {
 	var wl []string
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
 		wordLen := utf8.RuneCountInString(line)
 		if wordLen >= minLen && wordLen <= maxLen {
 			wl = append(wl, line)
@@ -127,44 +99,92 @@ func readAndFilterWords(filePath string, minLen int, maxLen int, fs embed.FS) ([
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, errors.Join(ErrReadFile, fmt.Errorf("failed to scan embedded text file (%s): %w", filePath, err))
+		return nil, errors.Join(ErrReadFile, err)
 	}
 
 	return wl, nil
 }
 
-// GetFilteredWordList reads a word list from an embedded file identified by the
+// GetFilteredWordList reads the word list registered in WordLists under the
 // given key, and filters the words based on the specified minimum and maximum
-// length, measured in runes. It returns a slice of strings that meet the
-// length criteria. If the
-// file cannot be opened or read, or if an error occurs during scanning, an
-// error is returned.
+// length, measured in runes. It returns a new slice of strings that meet the
+// length criteria, in word list order. If the word list cannot be found,
+// opened or read, or if an error occurs during scanning, an error is returned.
 func GetFilteredWordList(key string, minLen int, maxLen int) ([]string, error) {
-	filePath, err := getWordListFilePath(key)
+	wi, err := WordLists.index(key)
 	if err != nil {
 		return nil, err
 	}
 
-	return readAndFilterWords(filePath, minLen, maxLen, files)
+	return wi.filter(minLen, maxLen), nil
 }
 
-func getPresetFilePath(key string) (string, error) {
-	fileName, ok := keyToFile(key, option.ConfigKeyPreset)
-	if !ok {
-		return "", errors.Join(ErrInvalidPreset, fmt.Errorf("invalid %s value (%s)", option.ConfigKeyPreset, key))
+// GetWordListView returns the words of the word list registered in WordLists
+// under the given key which are between minLen and maxLen runes long,
+// inclusive. Each word list is read once and cached for the life of the
+// process, indexed by word length, so the returned slice is a view shared by
+// every caller and must not be modified. The words are in word list order when
+// the range covers every word, and sorted by length otherwise. If the word
+// list cannot be found, opened or read, an error is returned.
+func GetWordListView(key string, minLen int, maxLen int) ([]string, error) {
+	wi, err := WordLists.index(key)
+	if err != nil {
+		return nil, err
 	}
 
-	return path.Join(option.ConfigKeyPreset, fileName), nil
+	return wi.view(minLen, maxLen), nil
 }
 
-// GetJSONPreset reads a JSON preset file identified by the given key from
-// embedded files. It returns the content of the JSON file as a map, if not an
-// error is returned.
-func GetJSONPreset(key string) (map[string]any, error) {
-	filePath, err := getPresetFilePath(key)
-	if err != nil {
-		return nil, err
-	}
+// GetFilteredWordListFromReader reads a word list with one word per line from
+// an io.Reader, and filters the words based on the specified minimum and
+// maximum length, measured in runes. Carriage returns from CRLF endings are
+// stripped and empty lines are skipped, as for the embedded word lists. If an
+// error occurs while reading, an error is returned.
+func GetFilteredWordListFromReader(r io.Reader, minLen int, maxLen int) ([]string, error) {
+	return filterWords(r, minLen, maxLen)
+}
+
+// GetFilteredWordListFromFS reads a word list with one word per line from the
+// named file in the given file system, and filters the words based on the
+// specified minimum and maximum length, measured in runes. If the file cannot
+// be opened or read, an error is returned.
+func GetFilteredWordListFromFS(fsys fs.FS, name string, minLen int, maxLen int) ([]string, error) {
+	return readAndFilterWords(name, minLen, maxLen, fsys)
+}
+
+// GetFilteredWordListFromFile reads a word list with one word per line from
+// the file at the given path, and filters the words based on the specified
+// minimum and maximum length, measured in runes. If the file cannot be opened
+// or read, an error is returned.
+func GetFilteredWordListFromFile(filePath string, minLen int, maxLen int) ([]string, error) {
+	return readAndFilterWords(filePath, minLen, maxLen, osFS{})
+}
+
+// An fs.FS which opens files at any path of the operating system, unlike
+// os.DirFS which rejects absolute paths and paths outside its directory.
+type osFS struct{}
 
-	return loadJSONFileData(filePath, files.ReadFile)
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+// GetDefaultBlocklist returns the words of the embedded default blocklist, a
+// list of offensive words which shouldn't appear in generated passwords. If
+// the blocklist cannot be read, an error is returned.
+func GetDefaultBlocklist() ([]string, error) {
+	return readAndFilterWords("blocklist/default.txt", 1, math.MaxInt, files)
+}
+
+// GetCommonPINs returns the PINs of the embedded list of common PINs, the
+// most commonly chosen four digit PINs followed by common longer PINs, which
+// shouldn't be generated. If the list cannot be read, an error is returned.
+func GetCommonPINs() ([]string, error) {
+	return readAndFilterWords("pin/common.txt", 1, math.MaxInt, files)
+}
+
+// GetJSONPreset reads the JSON preset registered in Presets under the given
+// key. It returns the content of the preset as a map, if not an error is
+// returned.
+func GetJSONPreset(key string) (map[string]any, error) {
+	return loadJSONFileData(key, Presets.readAll)
 }
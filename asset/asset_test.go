@@ -3,8 +3,11 @@ package asset
 import (
 	"embed"
 	"errors"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/eljamo/libpass/v8/config/option"
 	"github.com/google/go-cmp/cmp"
@@ -15,30 +18,6 @@ import (
 //go:embed test_data/*
 var testFiles embed.FS
 
-func TestKeyToFile(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		key      string
-		fileType string
-		want     string
-		wantOk   bool
-	}{
-		{key: option.PresetAppleID, fileType: option.ConfigKeyPreset, want: "appleid.json", wantOk: true},
-		{key: option.WordListEN, fileType: option.ConfigKeyWordList, want: "en.txt", wantOk: true},
-		{key: "invalid", fileType: option.ConfigKeyPreset, want: "", wantOk: false},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.key, func(t *testing.T) {
-			t.Parallel()
-			got, ok := keyToFile(tt.key, tt.fileType)
-			if got != tt.want || ok != tt.wantOk {
-				t.Errorf("keyToFile(%q, %q) = %v, %v; want %v, %v", tt.key, tt.fileType, got, ok, tt.want, tt.wantOk)
-			}
-		})
-	}
-}
-
 func TestLoadJSONFileData(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -290,3 +269,139 @@ func TestGetWordList(t *testing.T) {
 		}
 	})
 }
+
+func TestGetFilteredWordListFromReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		input  string
+		minLen int
+		maxLen int
+		want   []string
+	}{
+		{
+			name:   "LF endings",
+			input:  "apple\nbanana\ncherry\n",
+			minLen: 1,
+			maxLen: 10,
+			want:   []string{"apple", "banana", "cherry"},
+		},
+		{
+			name:   "CRLF endings are stripped",
+			input:  "apple\r\nbanana\r\n",
+			minLen: 1,
+			maxLen: 10,
+			want:   []string{"apple", "banana"},
+		},
+		{
+			name:   "Empty lines are skipped even with a minimum length of 0",
+			input:  "apple\n\n\r\nbanana",
+			minLen: 0,
+			maxLen: 10,
+			want:   []string{"apple", "banana"},
+		},
+		{
+			name:   "Filtered by length in runes",
+			input:  "apple\nbanana\ndernière\n",
+			minLen: 6,
+			maxLen: 8,
+			want:   []string{"banana", "dernière"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := GetFilteredWordListFromReader(strings.NewReader(tt.input), tt.minLen, tt.maxLen)
+			if err != nil {
+				t.Fatalf("GetFilteredWordListFromReader() error = %v", err)
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("GetFilteredWordListFromReader() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetFilteredWordListFromFS(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"words.txt": &fstest.MapFile{Data: []byte("apple\r\nbanana\r\n\r\ncherry\r\n")},
+	}
+
+	got, err := GetFilteredWordListFromFS(fsys, "words.txt", 6, 6)
+	if err != nil {
+		t.Fatalf("GetFilteredWordListFromFS() error = %v", err)
+	}
+	if want := []string{"banana", "cherry"}; !cmp.Equal(got, want) {
+		t.Errorf("GetFilteredWordListFromFS() got = %v, want %v", got, want)
+	}
+
+	if _, err := GetFilteredWordListFromFS(fsys, "missing.txt", 1, 10); !errors.Is(err, ErrReadFile) {
+		t.Errorf("GetFilteredWordListFromFS(missing.txt) error = %v, want ErrReadFile", err)
+	}
+}
+
+func TestGetFilteredWordListFromFile(t *testing.T) {
+	t.Parallel()
+
+	got, err := GetFilteredWordListFromFile("test_data/words.txt", 6, 6)
+	if err != nil {
+		t.Fatalf("GetFilteredWordListFromFile() error = %v", err)
+	}
+	if want := []string{"banana", "cherry"}; !cmp.Equal(got, want) {
+		t.Errorf("GetFilteredWordListFromFile() got = %v, want %v", got, want)
+	}
+
+	abs, err := filepath.Abs("test_data/words.txt")
+	if err != nil {
+		t.Fatalf("filepath.Abs() error = %v", err)
+	}
+	if got, err := GetFilteredWordListFromFile(abs, 6, 6); err != nil || len(got) != 2 {
+		t.Errorf("GetFilteredWordListFromFile(%s) = %v, %v, want 2 words", abs, got, err)
+	}
+
+	if _, err := GetFilteredWordListFromFile("test_data/missing.txt", 1, 10); !errors.Is(err, ErrReadFile) {
+		t.Errorf("GetFilteredWordListFromFile(missing.txt) error = %v, want ErrReadFile", err)
+	}
+}
+
+func TestGetDefaultBlocklist(t *testing.T) {
+	t.Parallel()
+
+	words, err := GetDefaultBlocklist()
+	if err != nil {
+		t.Fatalf("GetDefaultBlocklist() error = %v", err)
+	}
+
+	for _, w := range words {
+		if w != strings.ToLower(strings.TrimSpace(w)) {
+			t.Errorf("GetDefaultBlocklist() word %q, want lower case words without spaces", w)
+		}
+	}
+
+	if !slices.Contains(words, "fuck") {
+		t.Error("GetDefaultBlocklist() doesn't contain an obvious offensive word")
+	}
+}
+
+func TestGetCommonPINs(t *testing.T) {
+	t.Parallel()
+
+	pins, err := GetCommonPINs()
+	if err != nil {
+		t.Fatalf("GetCommonPINs() error = %v", err)
+	}
+
+	for _, pin := range pins {
+		if strings.Trim(pin, "0123456789") != "" {
+			t.Errorf("GetCommonPINs() PIN %q, want only digits", pin)
+		}
+	}
+
+	if !slices.Contains(pins, "1234") {
+		t.Error("GetCommonPINs() doesn't contain the most common PIN")
+	}
+}
@@ -0,0 +1,118 @@
+package asset
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"unicode"
+
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+var ErrInvalidWordCharset = errors.New("invalid word charset")
+
+// WordCharsetFilter returns a function reporting whether a word is made only
+// of the characters allowed by a word_charset:
+//
+//   - ANY, or empty, allows every word
+//   - LETTERS allows letters of any script
+//   - ASCII_LETTERS allows the letters a to z in either case
+//   - LETTERS_HYPHEN allows letters of any script and hyphens
+//   - CUSTOM allows the characters matched by class, a regular expression
+//     character class such as [\p{Latin}']
+//
+// It returns an error if the charset is unknown or the class is invalid.
+func WordCharsetFilter(charset string, class string) (func(word string) bool, error) {
+	switch charset {
+	case "", option.WordCharsetAny:
+		return func(string) bool { return true }, nil
+	case option.WordCharsetLetters:
+		return allRunes(unicode.IsLetter), nil
+	case option.WordCharsetASCIILetters:
+		return allRunes(isASCIILetter), nil
+	case option.WordCharsetLettersHyphen:
+		return allRunes(func(r rune) bool { return r == '-' || unicode.IsLetter(r) }), nil
+	case option.WordCharsetCustom:
+		if class == "" {
+			return nil, errors.Join(ErrInvalidWordCharset, fmt.Errorf(
+				"%s must be set when %s is %s", option.ConfigKeyWordCharsetClass, option.ConfigKeyWordCharset, option.WordCharsetCustom,
+			))
+		}
+
+		re, err := regexp.Compile("^(?:" + class + ")+$")
+		if err != nil {
+			return nil, errors.Join(ErrInvalidWordCharset, fmt.Errorf("invalid %s value (%s): %w", option.ConfigKeyWordCharsetClass, class, err))
+		}
+
+		return re.MatchString, nil
+	}
+
+	return nil, errors.Join(ErrInvalidWordCharset, fmt.Errorf("invalid %s value (%s)", option.ConfigKeyWordCharset, charset))
+}
+
+// Returns a function reporting whether every rune of a word satisfies f.
+func allRunes(f func(rune) bool) func(string) bool {
+	return func(word string) bool {
+		for _, r := range word {
+			if !f(r) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+func isASCIILetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// WordCharsetLoss is the number of words a word list loses when words are
+// limited to a word_charset.
+type WordCharsetLoss struct {
+	// The name of the word list
+	WordList string
+	// The word_charset applied
+	Charset string
+	// The number of words in the word list
+	Words int
+	// The number of words left out because they contain other characters
+	Lost int
+}
+
+// WordCharsetReport reports how many words each of the named word lists, or
+// every word list registered in WordLists if none are named, loses under each
+// of the LETTERS, ASCII_LETTERS and LETTERS_HYPHEN word charsets. If a word
+// list cannot be read, an error is returned.
+func WordCharsetReport(names ...string) ([]WordCharsetLoss, error) {
+	if len(names) == 0 {
+		names = WordLists.Names()
+	}
+
+	charsets := []string{option.WordCharsetLetters, option.WordCharsetASCIILetters, option.WordCharsetLettersHyphen}
+
+	report := make([]WordCharsetLoss, 0, len(names)*len(charsets))
+	for _, name := range names {
+		wi, err := WordLists.index(name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, charset := range charsets {
+			keep, err := WordCharsetFilter(charset, "")
+			if err != nil {
+				return nil, err
+			}
+
+			loss := WordCharsetLoss{WordList: name, Charset: charset, Words: len(wi.words)}
+			for _, w := range wi.words {
+				if !keep(w) {
+					loss.Lost++
+				}
+			}
+			report = append(report, loss)
+		}
+	}
+
+	return report, nil
+}
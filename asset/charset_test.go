@@ -0,0 +1,96 @@
+package asset
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/eljamo/libpass/v8/config/option"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWordCharsetFilter(t *testing.T) {
+	t.Parallel()
+
+	words := []string{"apple", "Éclair", "well-known", "43m", "o'clock"}
+
+	tests := []struct {
+		name    string
+		charset string
+		class   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "Empty", charset: "", want: words},
+		{name: "Any", charset: option.WordCharsetAny, want: words},
+		{name: "Letters", charset: option.WordCharsetLetters, want: []string{"apple", "Éclair"}},
+		{name: "ASCII letters", charset: option.WordCharsetASCIILetters, want: []string{"apple"}},
+		{name: "Letters and hyphens", charset: option.WordCharsetLettersHyphen, want: []string{"apple", "Éclair", "well-known"}},
+		{name: "Custom", charset: option.WordCharsetCustom, class: `[a-z']`, want: []string{"apple", "o'clock"}},
+		{name: "Custom without a class", charset: option.WordCharsetCustom, wantErr: true},
+		{name: "Custom with an invalid class", charset: option.WordCharsetCustom, class: `[a-z`, wantErr: true},
+		{name: "Unknown", charset: "DIGITS", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			keep, err := WordCharsetFilter(tt.charset, tt.class)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WordCharsetFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidWordCharset) {
+					t.Errorf("WordCharsetFilter() error = %v, want ErrInvalidWordCharset", err)
+				}
+
+				return
+			}
+
+			var got []string
+			for _, w := range words {
+				if keep(w) {
+					got = append(got, w)
+				}
+			}
+
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("WordCharsetFilter(%q) kept %v, want %v", tt.charset, got, tt.want)
+			}
+		})
+	}
+}
+
+// Registers the word list of TestWordCharsetReport in the package registry
+// once, so the test can be run more than once, e.g. with -count=2.
+var registerCharsetReportWordList = sync.OnceValue(func() error {
+	return WordLists.Register("test_charset_report", "A charset report test list", BytesSource([]byte("apple\nÉclair\nwell-known\n43m\n")))
+})
+
+func TestWordCharsetReport(t *testing.T) {
+	t.Parallel()
+
+	if err := registerCharsetReportWordList(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	got, err := WordCharsetReport("TEST_CHARSET_REPORT")
+	if err != nil {
+		t.Fatalf("WordCharsetReport() error = %v", err)
+	}
+
+	want := []WordCharsetLoss{
+		{WordList: "TEST_CHARSET_REPORT", Charset: option.WordCharsetLetters, Words: 4, Lost: 2},
+		{WordList: "TEST_CHARSET_REPORT", Charset: option.WordCharsetASCIILetters, Words: 4, Lost: 3},
+		{WordList: "TEST_CHARSET_REPORT", Charset: option.WordCharsetLettersHyphen, Words: 4, Lost: 1},
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("WordCharsetReport() = %v, want %v", got, want)
+	}
+
+	if _, err := WordCharsetReport("MISSING"); !errors.Is(err, ErrInvalidWordList) {
+		t.Errorf("WordCharsetReport(MISSING) error = %v, want ErrInvalidWordList", err)
+	}
+}
@@ -1,60 +1,47 @@
 package asset
 
 import (
-	"slices"
 	"testing"
 
 	"github.com/eljamo/libpass/v8/config/option"
 )
 
-// These tests pin the three places a word list or preset must be registered
-// (asset fileMap, the option slice, and the option description map) to each
-// other, so adding one without the others fails CI.
+// These tests pin the deprecated option lists and description maps to the
+// built-in entries of the registries, so adding a built-in word list or
+// preset without them fails CI.
 
-func TestWordListOptionsMatchAssetRegistry(t *testing.T) {
+func TestWordListOptionsMatchBuiltins(t *testing.T) {
 	t.Parallel()
 
-	registry := fileMap[option.ConfigKeyWordList]
-	if got, want := len(option.WordLists), len(registry); got != want {
-		t.Errorf("option.WordLists has %d entries, asset registry has %d", got, want)
-	}
+	//nolint:staticcheck // Checks the deprecated lists still match
+	testOptionsMatchBuiltins(t, builtinWordLists, option.WordLists, option.WordListDescriptionMap)
+}
 
-	for _, wl := range option.WordLists {
-		if _, ok := registry[wl]; !ok {
-			t.Errorf("option.WordLists entry %q has no file in the asset registry", wl)
-		}
-		if _, ok := option.WordListDescriptionMap[wl]; !ok {
-			t.Errorf("option.WordLists entry %q has no entry in WordListDescriptionMap", wl)
-		}
-	}
+func TestPresetOptionsMatchBuiltins(t *testing.T) {
+	t.Parallel()
 
-	for key := range registry {
-		if !slices.Contains(option.WordLists, key) {
-			t.Errorf("asset registry word list %q is missing from option.WordLists", key)
-		}
-	}
+	//nolint:staticcheck // Checks the deprecated lists still match
+	testOptionsMatchBuiltins(t, builtinPresets, option.Presets, option.PresetDescriptionMap)
 }
 
-func TestPresetOptionsMatchAssetRegistry(t *testing.T) {
-	t.Parallel()
+func testOptionsMatchBuiltins(t *testing.T, builtins []builtinEntry, names []string, descriptions map[string]string) {
+	t.Helper()
 
-	registry := fileMap[option.ConfigKeyPreset]
-	if got, want := len(option.Presets), len(registry); got != want {
-		t.Errorf("option.Presets has %d entries, asset registry has %d", got, want)
+	if got, want := len(names), len(builtins); got != want {
+		t.Errorf("option list has %d entries, want %d", got, want)
 	}
 
-	for _, p := range option.Presets {
-		if _, ok := registry[p]; !ok {
-			t.Errorf("option.Presets entry %q has no file in the asset registry", p)
-		}
-		if _, ok := option.PresetDescriptionMap[p]; !ok {
-			t.Errorf("option.Presets entry %q has no entry in PresetDescriptionMap", p)
-		}
+	if got, want := len(descriptions), len(builtins); got != want {
+		t.Errorf("option description map has %d entries, want %d", got, want)
 	}
 
-	for key := range registry {
-		if !slices.Contains(option.Presets, key) {
-			t.Errorf("asset registry preset %q is missing from option.Presets", key)
+	for i, b := range builtins {
+		if i < len(names) && names[i] != b.name {
+			t.Errorf("option list entry %d = %q, want %q", i, names[i], b.name)
+		}
+
+		if got := descriptions[b.name]; got != b.description {
+			t.Errorf("option description of %q = %q, want %q", b.name, got, b.description)
 		}
 	}
 }
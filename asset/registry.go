@@ -0,0 +1,276 @@
+package asset
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"math"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+var ErrRegistry = errors.New("invalid registry entry")
+
+// Source opens the content of a word list, one word per line, or of a JSON
+// preset registered in a Registry.
+type Source func() (io.ReadCloser, error)
+
+// FSSource returns a Source which opens the named file in the given file
+// system.
+func FSSource(fsys fs.FS, name string) Source {
+	return func() (io.ReadCloser, error) {
+		f, err := fsys.Open(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file (%s): %w", name, err)
+		}
+
+		return f, nil
+	}
+}
+
+// FileSource returns a Source which opens the file at the given path.
+func FileSource(filePath string) Source {
+	return func() (io.ReadCloser, error) {
+		f, err := os.Open(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file (%s): %w", filePath, err)
+		}
+
+		return f, nil
+	}
+}
+
+// BytesSource returns a Source which reads the given content. The content must
+// not be modified after it is registered.
+func BytesSource(b []byte) Source {
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}
+}
+
+type registryEntry struct {
+	description string
+	source      Source
+}
+
+// Registry holds the named word lists or presets which can be selected by
+// configuration. Names are case-insensitive and are stored upper case. A
+// Registry is safe for concurrent use, so applications can register their own
+// entries at startup alongside the built-in ones.
+type Registry struct {
+	key        string // The configuration key which selects an entry, used in error messages
+	errInvalid error  // The error returned when an unregistered name is looked up
+
+	mu      sync.RWMutex
+	names   []string
+	entries map[string]registryEntry
+
+	indexes sync.Map // The parsed word lists, keyed by name, see Registry.index
+}
+
+// builtinEntry describes a file embedded in the module to be registered by
+// default.
+type builtinEntry struct {
+	name        string
+	description string
+	file        string
+}
+
+// WordLists is the registry of word lists which can be selected with the
+// word_list key. It contains the built-in word lists by default.
+var WordLists = newRegistry(option.ConfigKeyWordList, ErrInvalidWordList, builtinWordLists)
+
+// The built-in word lists, which option.WordLists and
+// option.WordListDescriptionMap also list for backward compatibility.
+var builtinWordLists = []builtinEntry{
+	{option.WordList40k, "A Warhammer 40k word list (8600+ words)", "40k.txt"},
+	{option.WordListAll, "A combination of all the word lists (60000+ words)", "all.txt"},
+	{option.WordListDoctorWho, "A Doctor Who word list (11300+ words)", "doctor_who.txt"},
+	{option.WordListEN, "A list of English words (14800+ words)", "en.txt"},
+	{option.WordListENSmall, "A small list of English words (8600+ words)", "en_small.txt"},
+	{option.WordListGameOfThrones, "A Game of Thrones word list (8200+ words)", "game_of_thrones.txt"},
+	{option.WordListHarryPotter, "A Harry Potter word list (12600+ words)", "harry_potter.txt"},
+	{option.WordListMiddleEarth, "A Middle Earth word list containing words from The Hobbit, Lord of the Rings, The Silmarillion, and more (15400+ words)", "middle_earth.txt"},
+	{option.WordListPokemon, "A Pokemon word list (9000+ words)", "pokemon.txt"},
+	{option.WordListStarTrek, "A Star Trek word list (8000+ words)", "star_trek.txt"},
+	{option.WordListStarWars, "A Star Wars word list (12100+ words)", "star_wars.txt"},
+	{option.WordListSunborn, "A Sunborn word list (31300+ words)", "sunborn.txt"},
+	{option.WordListEFFLarge, "The EFF large diceware word list, in dice roll order, 5 rolls per word (7776 words)", "eff_large.txt"},
+	{option.WordListEFFShort, "The EFF short diceware word list with unique three letter prefixes, in dice roll order, 4 rolls per word (1296 words)", "eff_short.txt"},
+	{option.WordListAdjectives, "A list of common English adjectives, for the adjective slots of grammatical passphrases (480+ words)", "adjectives.txt"},
+	{option.WordListAdverbs, "A list of common English adverbs, for the adverb slots of grammatical passphrases (190+ words)", "adverbs.txt"},
+	{option.WordListNouns, "A list of common English nouns, mostly animals and things, for the noun slots of grammatical passphrases (410+ words)", "nouns.txt"},
+	{option.WordListVerbs, "A list of common English verbs in the third person singular, for the verb slots of grammatical passphrases (350+ words)", "verbs.txt"},
+}
+
+// Presets is the registry of JSON presets which can be selected with the
+// preset key. It contains the built-in presets by default.
+var Presets = newRegistry(option.ConfigKeyPreset, ErrInvalidPreset, builtinPresets)
+
+// The built-in presets, which option.Presets and option.PresetDescriptionMap
+// also list for backward compatibility.
+var builtinPresets = []builtinEntry{
+	{option.PresetDefault, "The default preset resulting in a password consisting of 3 random words of between 4 and 8 letters with alternating case separated by a random character, with two random digits before and after, and padded with two random characters front and back", "default.json"},
+	{option.PresetAppleID, "A preset respecting the many prerequisites Apple places on Apple ID passwords. The preset also limits itself to symbols found on the iOS letter and number keyboards (i.e. not the awkward to reach symbol keyboard)", "appleid.json"},
+	{option.PresetNTLM, "A preset for 14 character Windows NTLMv1 password. WARNING - only use this preset if you have to, it is too short to be acceptably secure", "ntlm.json"},
+	{option.PresetSecurityQ, "A preset for creating fake answers to security questions", "securityq.json"},
+	{option.PresetWeb16, "A preset for websites that insist passwords not be longer than 16 characters", "web16.json"},
+	{option.PresetWeb16XKPasswd, "A preset for websites that insist passwords not be longer than 16 characters, the same as the one found on xkpasswd.net.", "web16_xkpasswd.json"},
+	{option.PresetWeb32, "A preset for websites that allow passwords up to 32 characteres long", "web32.json"},
+	{option.PresetWiFi, "A preset for generating 63 character long WPA2 keys", "wifi.json"},
+	{option.PresetXKCD, "A preset for generating passwords similar to the example in the original XKCD cartoon, but with a dash to separate the four randomly capitalised words, two digits and a random special characters.", "xkcd.json"},
+	{option.PresetXKCDXKPasswd, "A preset for generating passwords similar to the example in the original XKCD cartoon, but with a dash to separate the four random words, and the capitalisation randomised to add sufficient entropy to avoid warnings.", "xkcd_xkpasswd.json"},
+	{option.PresetRandom32, "A preset for 32 character passwords of random letters, digits and symbols, with at least one of each, for service accounts and database users which need opaque strings rather than passphrases", "random32.json"},
+}
+
+// Creates a registry for the given configuration key with the built-in
+// entries, read from the embedded directory named after the key, registered.
+func newRegistry(key string, errInvalid error, builtins []builtinEntry) *Registry {
+	r := &Registry{
+		key:        key,
+		errInvalid: errInvalid,
+		entries:    make(map[string]registryEntry, len(builtins)),
+	}
+
+	for _, b := range builtins {
+		if err := r.Register(b.name, b.description, FSSource(files, path.Join(key, b.file))); err != nil {
+			panic(err)
+		}
+	}
+
+	return r
+}
+
+// Register adds an entry with the given name, description and source to the
+// registry, making it available to configuration under that name. It returns
+// an error if the name is empty or already registered, or the source is nil.
+func (r *Registry) Register(name string, description string, source Source) error {
+	key := strings.ToUpper(name)
+	if key == "" {
+		return errors.Join(ErrRegistry, fmt.Errorf("%s name cannot be empty", r.key))
+	}
+
+	if source == nil {
+		return errors.Join(ErrRegistry, fmt.Errorf("%s (%s) source cannot be nil", r.key, key))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.entries[key]; ok {
+		return errors.Join(ErrRegistry, fmt.Errorf("%s (%s) is already registered", r.key, key))
+	}
+
+	r.names = append(r.names, key)
+	r.entries[key] = registryEntry{description, source}
+
+	return nil
+}
+
+// Names returns the names of the registered entries in the order they were
+// registered, built-in entries first.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, len(r.names))
+	copy(names, r.names)
+
+	return names
+}
+
+// Has reports whether an entry is registered under the given name.
+func (r *Registry) Has(name string) bool {
+	_, ok := r.lookup(name)
+
+	return ok
+}
+
+// Description returns the description of the entry registered under the given
+// name, and whether there is one.
+func (r *Registry) Description(name string) (string, bool) {
+	e, ok := r.lookup(name)
+
+	return e.description, ok
+}
+
+func (r *Registry) lookup(name string) (registryEntry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.entries[strings.ToUpper(name)]
+
+	return e, ok
+}
+
+// Opens the content of the entry registered under the given name. It returns
+// an error if there is no such entry or it cannot be opened.
+func (r *Registry) open(name string) (io.ReadCloser, error) {
+	e, ok := r.lookup(name)
+	if !ok {
+		return nil, errors.Join(r.errInvalid, fmt.Errorf("invalid %s value (%s)", r.key, name))
+	}
+
+	rc, err := e.source()
+	if err != nil {
+		return nil, errors.Join(ErrReadFile, fmt.Errorf("failed to open %s (%s): %w", r.key, name, err))
+	}
+
+	return rc, nil
+}
+
+// Reads the whole content of the entry registered under the given name.
+func (r *Registry) readAll(name string) ([]byte, error) {
+	rc, err := r.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer closeSource(rc, name)
+
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s (%s): %w", r.key, name, err)
+	}
+
+	return b, nil
+}
+
+// Returns the word list registered under the given name parsed into a
+// wordIndex. Each word list is parsed the first time it is requested and
+// cached for the life of the process, entries cannot be replaced once
+// registered so the cache never goes stale. Failures are not cached.
+func (r *Registry) index(name string) (*wordIndex, error) {
+	key := strings.ToUpper(name)
+	if wi, ok := r.indexes.Load(key); ok {
+		return wi.(*wordIndex), nil //nolint:forcetypeassert // Only *wordIndex values are stored
+	}
+
+	rc, err := r.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer closeSource(rc, name)
+
+	words, err := filterWords(rc, 1, math.MaxInt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s (%s): %w", r.key, name, err)
+	}
+
+	// Concurrent first requests may both parse the word list, only one index is kept
+	wi, _ := r.indexes.LoadOrStore(key, newWordIndex(words))
+
+	return wi.(*wordIndex), nil //nolint:forcetypeassert // Only *wordIndex values are stored
+}
+
+func closeSource(c io.Closer, name string) {
+	if err := c.Close(); err != nil {
+		log.Printf("failed to close source (%s): %v", name, err)
+	}
+}
@@ -0,0 +1,178 @@
+package asset
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/eljamo/libpass/v8/config/option"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuiltinWordListsRegistered(t *testing.T) {
+	t.Parallel()
+
+	want := []string{
+		option.WordList40k, option.WordListAll, option.WordListDoctorWho, option.WordListEN,
+		option.WordListENSmall, option.WordListGameOfThrones, option.WordListHarryPotter,
+		option.WordListMiddleEarth, option.WordListPokemon, option.WordListStarTrek,
+		option.WordListStarWars, option.WordListSunborn, option.WordListEFFLarge, option.WordListEFFShort,
+		option.WordListAdjectives, option.WordListAdverbs, option.WordListNouns, option.WordListVerbs,
+	}
+
+	testBuiltinsRegistered(t, WordLists, want)
+}
+
+func TestBuiltinPresetsRegistered(t *testing.T) {
+	t.Parallel()
+
+	want := []string{
+		option.PresetDefault, option.PresetAppleID, option.PresetNTLM, option.PresetSecurityQ,
+		option.PresetWeb16, option.PresetWeb16XKPasswd, option.PresetWeb32, option.PresetWiFi,
+		option.PresetXKCD, option.PresetXKCDXKPasswd, option.PresetRandom32,
+	}
+
+	testBuiltinsRegistered(t, Presets, want)
+}
+
+// testBuiltinsRegistered checks every built-in name is registered, in order,
+// with a description and a source which opens.
+func testBuiltinsRegistered(t *testing.T, r *Registry, want []string) {
+	t.Helper()
+
+	if got := r.Names(); !slices.Equal(got[:min(len(got), len(want))], want) {
+		t.Fatalf("Names() = %v, want it to start with %v", got, want)
+	}
+
+	for _, name := range want {
+		if d, ok := r.Description(name); !ok || d == "" {
+			t.Errorf("Description(%q) = %q, %v, want a description", name, d, ok)
+		}
+
+		if _, err := r.readAll(name); err != nil {
+			t.Errorf("readAll(%q) error = %v", name, err)
+		}
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	t.Parallel()
+
+	src := BytesSource([]byte("apple\nbanana\n"))
+
+	tests := []struct {
+		name    string
+		entry   string
+		source  Source
+		wantErr bool
+	}{
+		{name: "Valid entry", entry: "fruit", source: src, wantErr: false},
+		{name: "Duplicate entry", entry: "FRUIT", source: src, wantErr: true},
+		{name: "Empty name", entry: "", source: src, wantErr: true},
+		{name: "Nil source", entry: "vegetables", source: nil, wantErr: true},
+	}
+
+	r := newRegistry(option.ConfigKeyWordList, ErrInvalidWordList, nil)
+
+	// Not parallel, the cases depend on the entries registered before them
+	for _, tt := range tests {
+		err := r.Register(tt.entry, "A test entry", tt.source)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Register() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if tt.wantErr && !errors.Is(err, ErrRegistry) {
+			t.Errorf("%s: Register() error = %v, want ErrRegistry", tt.name, err)
+		}
+	}
+
+	if got, want := r.Names(), []string{"FRUIT"}; !slices.Equal(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+
+	if !r.Has("Fruit") {
+		t.Error("Has(Fruit) = false, want names to be case-insensitive")
+	}
+
+	if _, err := r.open("missing"); !errors.Is(err, ErrInvalidWordList) {
+		t.Errorf("open(missing) error = %v, want ErrInvalidWordList", err)
+	}
+}
+
+func TestRegistryOpenSourceError(t *testing.T) {
+	t.Parallel()
+
+	r := newRegistry(option.ConfigKeyWordList, ErrInvalidWordList, nil)
+	if err := r.Register("broken", "A broken entry", FSSource(fstest.MapFS{}, "missing.txt")); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	if _, err := r.open("broken"); !errors.Is(err, ErrReadFile) {
+		t.Errorf("open(broken) error = %v, want ErrReadFile", err)
+	}
+}
+
+// Registers the entries of TestRegisteredEntriesAreUsable in the package
+// registries once, as names can't be registered again when the tests are run
+// more than once, e.g. with -count=2.
+var registerTestEntries = sync.OnceValue(func() error {
+	if err := WordLists.Register("test_registry_words", "A test word list", BytesSource([]byte("apple\r\nkiwi\r\n"))); err != nil {
+		return err
+	}
+
+	preset := fstest.MapFS{"preset.json": &fstest.MapFile{Data: []byte(`{"num_words": 5}`)}}
+
+	return Presets.Register("test_registry_preset", "A test preset", FSSource(preset, "preset.json"))
+})
+
+func TestRegisteredEntriesAreUsable(t *testing.T) {
+	t.Parallel()
+
+	if err := registerTestEntries(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	words, err := GetFilteredWordList("TEST_REGISTRY_WORDS", 1, 4)
+	if err != nil {
+		t.Fatalf("GetFilteredWordList() error = %v", err)
+	}
+	if want := []string{"kiwi"}; !cmp.Equal(words, want) {
+		t.Errorf("GetFilteredWordList() = %v, want %v", words, want)
+	}
+
+	got, err := GetJSONPreset("TEST_REGISTRY_PRESET")
+	if err != nil {
+		t.Fatalf("GetJSONPreset() error = %v", err)
+	}
+	if want := map[string]any{"num_words": float64(5)}; !cmp.Equal(got, want) {
+		t.Errorf("GetJSONPreset() = %v, want %v", got, want)
+	}
+
+	if !slices.Contains(Presets.Names(), "TEST_REGISTRY_PRESET") {
+		t.Errorf("Presets.Names() = %v, want it to contain TEST_REGISTRY_PRESET", Presets.Names())
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	t.Parallel()
+
+	rc, err := FileSource("test_data/words.txt")()
+	if err != nil {
+		t.Fatalf("FileSource() error = %v", err)
+	}
+	defer rc.Close()
+
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("io.ReadAll() error = %v", err)
+	}
+	if len(b) == 0 {
+		t.Error("FileSource() read no content")
+	}
+
+	if _, err := FileSource("test_data/missing.txt")(); err == nil {
+		t.Error("FileSource(missing.txt) error = nil, want an error")
+	}
+}
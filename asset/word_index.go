@@ -0,0 +1,85 @@
+package asset
+
+import (
+	"cmp"
+	"slices"
+	"unicode/utf8"
+)
+
+// wordIndex is a word list parsed once, with its words indexed by their length
+// in runes so any range of lengths can be handed out without copying.
+type wordIndex struct {
+	words    []string // The words in the order they appear in the word list
+	byLength []string // The words sorted by length, in word list order within a length
+	offsets  []int    // offsets[n] is the index in byLength of the first word of n or more runes
+}
+
+// Creates a wordIndex from the words of a word list.
+func newWordIndex(words []string) *wordIndex {
+	byLength := slices.Clone(words)
+	slices.SortStableFunc(byLength, func(a, b string) int {
+		return cmp.Compare(utf8.RuneCountInString(a), utf8.RuneCountInString(b))
+	})
+
+	maxLen := 0
+	if len(byLength) > 0 {
+		maxLen = utf8.RuneCountInString(byLength[len(byLength)-1])
+	}
+
+	// One past the longest length, so offsets[maxLen+1] marks the end of byLength
+	offsets := make([]int, maxLen+2)
+	i := 0
+	for n := range offsets {
+		for i < len(byLength) && utf8.RuneCountInString(byLength[i]) < n {
+			i++
+		}
+		offsets[n] = i
+	}
+
+	return &wordIndex{words, byLength, offsets}
+}
+
+// view returns the words between minLen and maxLen runes long, inclusive. The
+// returned slice shares memory with the index and its capacity is capped at its
+// length, so appending to it never writes into the index. When the range
+// covers every word, the words are in word list order, otherwise they are
+// sorted by length.
+func (wi *wordIndex) view(minLen int, maxLen int) []string {
+	lo := wi.offset(minLen)
+	hi := len(wi.byLength)
+	if maxLen < len(wi.offsets)-1 {
+		hi = max(lo, wi.offset(maxLen+1))
+	}
+
+	if lo == 0 && hi == len(wi.byLength) {
+		return wi.words[:len(wi.words):len(wi.words)]
+	}
+
+	return wi.byLength[lo:hi:hi]
+}
+
+// offset returns the index in byLength of the first word of n or more runes.
+func (wi *wordIndex) offset(n int) int {
+	if n < 0 {
+		return 0
+	}
+
+	if n >= len(wi.offsets) {
+		return len(wi.byLength)
+	}
+
+	return wi.offsets[n]
+}
+
+// filter returns a new slice of the words between minLen and maxLen runes
+// long, inclusive, in word list order.
+func (wi *wordIndex) filter(minLen int, maxLen int) []string {
+	var wl []string
+	for _, w := range wi.words {
+		if n := utf8.RuneCountInString(w); n >= minLen && n <= maxLen {
+			wl = append(wl, w)
+		}
+	}
+
+	return wl
+}
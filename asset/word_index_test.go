@@ -0,0 +1,153 @@
+package asset
+
+import (
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/eljamo/libpass/v8/config/option"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWordIndexView(t *testing.T) {
+	t.Parallel()
+
+	wi := newWordIndex([]string{"ccc", "a", "dddd", "bb", "ééé", "ee"})
+
+	tests := []struct {
+		name   string
+		minLen int
+		maxLen int
+		want   []string
+	}{
+		{name: "Every word keeps list order", minLen: 1, maxLen: 4, want: []string{"ccc", "a", "dddd", "bb", "ééé", "ee"}},
+		{name: "Unbounded range keeps list order", minLen: math.MinInt, maxLen: math.MaxInt, want: []string{"ccc", "a", "dddd", "bb", "ééé", "ee"}},
+		{name: "Single length", minLen: 2, maxLen: 2, want: []string{"bb", "ee"}},
+		{name: "Lengths counted in runes", minLen: 3, maxLen: 3, want: []string{"ccc", "ééé"}},
+		{name: "Range sorted by length", minLen: 2, maxLen: 10, want: []string{"bb", "ee", "ccc", "ééé", "dddd"}},
+		{name: "Range longer than every word", minLen: 5, maxLen: 10, want: []string{}},
+		{name: "Inverted range", minLen: 3, maxLen: 2, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := wi.view(tt.minLen, tt.maxLen)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("view(%d, %d) = %v, want %v", tt.minLen, tt.maxLen, got, tt.want)
+			}
+
+			if cap(got) != len(got) {
+				t.Errorf("view(%d, %d) capacity = %d, want %d", tt.minLen, tt.maxLen, cap(got), len(got))
+			}
+		})
+	}
+}
+
+func TestGetWordListViewIsShared(t *testing.T) {
+	t.Parallel()
+
+	a, err := GetWordListView(option.WordListENSmall, 4, 6)
+	if err != nil {
+		t.Fatalf("GetWordListView() error = %v", err)
+	}
+
+	b, err := GetWordListView(strings.ToLower(option.WordListENSmall), 4, 6)
+	if err != nil {
+		t.Fatalf("GetWordListView() error = %v", err)
+	}
+
+	if len(a) == 0 || &a[0] != &b[0] {
+		t.Error("GetWordListView() returned a copy, want a view of the cached word list")
+	}
+
+	filtered, err := GetFilteredWordList(option.WordListENSmall, 4, 6)
+	if err != nil {
+		t.Fatalf("GetFilteredWordList() error = %v", err)
+	}
+
+	if len(filtered) != len(a) {
+		t.Errorf("GetWordListView() returned %d words, want %d", len(a), len(filtered))
+	}
+}
+
+func TestGetWordListViewConcurrent(t *testing.T) {
+	t.Parallel()
+
+	var wg sync.WaitGroup
+	views := make([][]string, 8)
+	for i := range views {
+		wg.Go(func() {
+			v, err := GetWordListView(option.WordListStarTrek, 1, 5)
+			if err != nil {
+				t.Errorf("GetWordListView() error = %v", err)
+			}
+			views[i] = v
+		})
+	}
+	wg.Wait()
+
+	for _, v := range views[1:] {
+		if len(v) == 0 || &v[0] != &views[0][0] {
+			t.Fatal("GetWordListView() returned different views for the same word list")
+		}
+	}
+}
+
+func TestRegistryIndexDoesNotCacheFailures(t *testing.T) {
+	t.Parallel()
+
+	r := newRegistry(option.ConfigKeyWordList, ErrInvalidWordList, nil)
+
+	calls := 0
+	source := func() (io.ReadCloser, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("temporary failure")
+		}
+
+		return BytesSource([]byte("one\ntwo\n"))()
+	}
+
+	if err := r.Register("flaky", "A flaky word list", source); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	if _, err := r.index("flaky"); err == nil {
+		t.Fatal("index() error = nil, want the source error")
+	}
+
+	wi, err := r.index("flaky")
+	if err != nil {
+		t.Fatalf("index() error = %v", err)
+	}
+
+	if want := []string{"one", "two"}; !cmp.Equal(wi.words, want) {
+		t.Errorf("index() words = %v, want %v", wi.words, want)
+	}
+
+	if _, err := r.index("flaky"); err != nil || calls != 2 {
+		t.Errorf("index() opened the source %d times, want 2", calls)
+	}
+}
+
+func BenchmarkGetWordListView(b *testing.B) {
+	for b.Loop() {
+		if _, err := GetWordListView(option.WordListAll, 4, 8); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadAndFilterWords(b *testing.B) {
+	for b.Loop() {
+		if _, err := readAndFilterWords("word_list/all.txt", 4, 8, files); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -2,39 +2,70 @@ package option
 
 // Config key
 const (
-	ConfigKeyCaseTransform           string = "case_transform"
-	ConfigKeyNumPasswords            string = "num_passwords"
-	ConfigKeyNumWords                string = "num_words"
-	ConfigKeyPaddingCharactersAfter  string = "padding_characters_after"
-	ConfigKeyPaddingCharactersBefore string = "padding_characters_before"
-	ConfigKeyPaddingCharacter        string = "padding_character"
-	ConfigKeyPaddingDigitsAfter      string = "padding_digits_after"
-	ConfigKeyPaddingDigitsBefore     string = "padding_digits_before"
-	ConfigKeyPaddingType             string = "padding_type"
-	ConfigKeyPadToLength             string = "pad_to_length"
-	ConfigKeyPreset                  string = "preset"
-	ConfigKeySeparatorAlphabet       string = "separator_alphabet"
-	ConfigKeySeparatorCharacter      string = "separator_character"
-	ConfigKeySymbolAlphabet          string = "symbol_alphabet"
-	ConfigKeyWordLengthMax           string = "word_length_max"
-	ConfigKeyWordLengthMin           string = "word_length_min"
-	ConfigKeyWordList                string = "word_list"
+	ConfigKeyAvoidAmbiguous            string = "avoid_ambiguous"
+	ConfigKeyBlocklist                 string = "blocklist"
+	ConfigKeyBlocklistDefault          string = "blocklist_default"
+	ConfigKeyBlocklistFile             string = "blocklist_file"
+	ConfigKeyBlocklistPatterns         string = "blocklist_patterns"
+	ConfigKeyCaseTransform             string = "case_transform"
+	ConfigKeyCharacterClasses          string = "character_classes"
+	ConfigKeyCharacterSubstitutionMode string = "character_substitution_mode"
+	ConfigKeyCharacterSubstitutions    string = "character_substitutions"
+	ConfigKeyCustomCharacters          string = "custom_characters"
+	ConfigKeyGenerator                 string = "generator"
+	ConfigKeyGrammar                   string = "grammar"
+	ConfigKeyLength                    string = "length"
+	ConfigKeyMinEntropyBits            string = "min_entropy_bits"
+	ConfigKeyNumPasswords              string = "num_passwords"
+	ConfigKeyNumWords                  string = "num_words"
+	ConfigKeyPaddingCharactersAfter    string = "padding_characters_after"
+	ConfigKeyPaddingCharactersBefore   string = "padding_characters_before"
+	ConfigKeyPaddingCharacter          string = "padding_character"
+	ConfigKeyPaddingDigitsAfter        string = "padding_digits_after"
+	ConfigKeyPaddingDigitsBefore       string = "padding_digits_before"
+	ConfigKeyPaddingOverflow           string = "padding_overflow"
+	ConfigKeyPaddingPosition           string = "padding_position"
+	ConfigKeyPaddingType               string = "padding_type"
+	ConfigKeyPadToLength               string = "pad_to_length"
+	ConfigKeyPattern                   string = "pattern"
+	ConfigKeyPolicy                    string = "policy"
+	ConfigKeyPreset                    string = "preset"
+	ConfigKeySeparatorAlphabet         string = "separator_alphabet"
+	ConfigKeySeparatorCharacter        string = "separator_character"
+	ConfigKeySeparatorMode             string = "separator_mode"
+	ConfigKeySymbolAlphabet            string = "symbol_alphabet"
+	ConfigKeyUniqueWordPrefixLength    string = "unique_word_prefix_length"
+	ConfigKeyUniqueWords               string = "unique_words"
+	ConfigKeyWordCharset               string = "word_charset"
+	ConfigKeyWordCharsetClass          string = "word_charset_class"
+	ConfigKeyWordLengthMax             string = "word_length_max"
+	ConfigKeyWordLengthMin             string = "word_length_min"
+	ConfigKeyWordList                  string = "word_list"
+	ConfigKeyWordListFile              string = "word_list_file"
+	ConfigKeyWordListSlots             string = "word_list_slots"
+	ConfigKeyWordListWeights           string = "word_list_weights"
 )
 
 // Word list constant
 const (
 	WordList40k           string = "40K"
+	WordListAdjectives    string = "ADJECTIVES"
+	WordListAdverbs       string = "ADVERBS"
 	WordListAll           string = "ALL"
 	WordListDoctorWho     string = "DOCTOR_WHO"
+	WordListEFFLarge      string = "EFF_LARGE"
+	WordListEFFShort      string = "EFF_SHORT"
 	WordListEN            string = "EN"
 	WordListENSmall       string = "EN_SMALL"
 	WordListGameOfThrones string = "GAME_OF_THRONES"
 	WordListHarryPotter   string = "HARRY_POTTER"
 	WordListMiddleEarth   string = "MIDDLE_EARTH"
+	WordListNouns         string = "NOUNS"
 	WordListPokemon       string = "POKEMON"
 	WordListStarTrek      string = "STAR_TREK"
 	WordListStarWars      string = "STAR_WARS"
 	WordListSunborn       string = "SUNBORN"
+	WordListVerbs         string = "VERBS"
 )
 
 // Preset constant
@@ -42,6 +73,7 @@ const (
 	PresetAppleID       string = "APPLEID"
 	PresetDefault       string = "DEFAULT"
 	PresetNTLM          string = "NTLM"
+	PresetRandom32      string = "RANDOM32"
 	PresetSecurityQ     string = "SECURITYQ"
 	PresetWeb16         string = "WEB16"
 	PresetWeb16XKPasswd string = "WEB16_XKPASSWD"
@@ -73,6 +105,19 @@ const (
 	PaddingTypeNone     string = "NONE"
 )
 
+// Padding overflow constant
+const (
+	PaddingOverflowReject   string = "REJECT"
+	PaddingOverflowTruncate string = "TRUNCATE"
+)
+
+// Padding position constant
+const (
+	PaddingPositionAfter  string = "AFTER"
+	PaddingPositionBefore string = "BEFORE"
+	PaddingPositionBoth   string = "BOTH"
+)
+
 const (
 	PaddingCharacterRandom string = "RANDOM"
 )
@@ -80,3 +125,61 @@ const (
 const (
 	SeparatorCharacterRandom string = "RANDOM"
 )
+
+// Separator mode constant
+const (
+	SeparatorModePerGap string = "PER_GAP"
+	SeparatorModeSingle string = "SINGLE"
+)
+
+// Word charset constant
+const (
+	WordCharsetAny           string = "ANY"
+	WordCharsetASCIILetters  string = "ASCII_LETTERS"
+	WordCharsetCustom        string = "CUSTOM"
+	WordCharsetLetters       string = "LETTERS"
+	WordCharsetLettersHyphen string = "LETTERS_HYPHEN"
+)
+
+// Generator constant
+const (
+	GeneratorCharacters    string = "CHARACTERS"
+	GeneratorGrammatical   string = "GRAMMATICAL"
+	GeneratorPIN           string = "PIN"
+	GeneratorPronounceable string = "PRONOUNCEABLE"
+	GeneratorWords         string = "WORDS"
+)
+
+// Character class constant
+const (
+	CharacterClassCustom  string = "CUSTOM"
+	CharacterClassDigits  string = "DIGITS"
+	CharacterClassLower   string = "LOWER"
+	CharacterClassSymbols string = "SYMBOLS"
+	CharacterClassUpper   string = "UPPER"
+)
+
+// Character substitution mode constant
+const (
+	CharacterSubstitutionModeAlways     string = "ALWAYS"
+	CharacterSubstitutionModeAtLeastOne string = "AT_LEAST_ONE"
+	CharacterSubstitutionModeRandom     string = "RANDOM"
+)
+
+// Policy key, the keys of the policy setting
+const (
+	PolicyKeyForbiddenCharacters   string = "forbidden_characters"
+	PolicyKeyMaxLength             string = "max_length"
+	PolicyKeyMaxRepeatedCharacters string = "max_repeated_characters"
+	PolicyKeyMinLength             string = "min_length"
+	PolicyKeyMustStartWithLetter   string = "must_start_with_letter"
+	PolicyKeyRequired              string = "required"
+)
+
+// Part of speech constant
+const (
+	PartOfSpeechAdjective string = "ADJECTIVE"
+	PartOfSpeechAdverb    string = "ADVERB"
+	PartOfSpeechNoun      string = "NOUN"
+	PartOfSpeechVerb      string = "VERB"
+)
@@ -1,9 +1,13 @@
 package option
 
-// A slice of available presets
+// A slice of the built-in presets
+//
+// Deprecated: Use asset.Presets.Names, which also lists the presets
+// registered at runtime.
 var Presets = []string{
 	PresetDefault, PresetAppleID, PresetNTLM, PresetSecurityQ, PresetWeb16,
 	PresetWeb16XKPasswd, PresetWeb32, PresetWiFi, PresetXKCD, PresetXKCDXKPasswd,
+	PresetRandom32,
 }
 
 // A slice of special characters which can be used for padding and separator
@@ -13,9 +17,51 @@ var DefaultSpecialCharacters = []string{
 	".", ";",
 }
 
+// A slice of characters which are easily mistaken for one another when read,
+// left out of passwords when avoid_ambiguous is set
+var AmbiguousCharacters = []string{"0", "1", "I", "O", "l", "|"}
+
+// A slice of character sequences which are easily mistaken for another
+// character when read, such as rn for m, left out of words when
+// avoid_ambiguous is set
+var AmbiguousSequences = []string{"rn"}
+
+// A slice of available options for the kind of password to generate
+var Generators = []string{
+	GeneratorCharacters, GeneratorGrammatical, GeneratorPIN, GeneratorPronounceable, GeneratorWords,
+}
+
+// A slice of available parts of speech for the GRAMMATICAL generator
+var PartsOfSpeech = []string{PartOfSpeechAdjective, PartOfSpeechAdverb, PartOfSpeechNoun, PartOfSpeechVerb}
+
+// The parts of speech of the words of a grammatical passphrase when grammar
+// isn't set, repeated for passphrases of more words, e.g.
+// purple-otter-juggles-quietly
+var DefaultGrammar = []string{PartOfSpeechAdjective, PartOfSpeechNoun, PartOfSpeechVerb, PartOfSpeechAdverb}
+
+// A slice of available character classes for the CHARACTERS generator
+var CharacterClasses = []string{
+	CharacterClassCustom, CharacterClassDigits, CharacterClassLower, CharacterClassSymbols, CharacterClassUpper,
+}
+
+// A slice of available options for how character_substitutions are applied
+var CharacterSubstitutionModes = []string{
+	CharacterSubstitutionModeAlways, CharacterSubstitutionModeAtLeastOne, CharacterSubstitutionModeRandom,
+}
+
+// The leetspeak substitutions of xkpasswd, a map which can be used for the
+// character_substitutions setting
+var DefaultCharacterSubstitutions = map[string]string{"a": "@", "e": "3", "o": "0", "s": "$"}
+
 // A slice of available options for padding
 var PaddingTypes = []string{PaddingTypeAdaptive, PaddingTypeFixed, PaddingTypeNone}
 
+// A slice of available options for passwords longer than pad_to_length
+var PaddingOverflows = []string{PaddingOverflowReject, PaddingOverflowTruncate}
+
+// A slice of available options for where adaptive padding is added
+var PaddingPositions = []string{PaddingPositionAfter, PaddingPositionBefore, PaddingPositionBoth}
+
 // A slice of available options for case transformation
 var TransformTypes = []string{
 	CaseTransformAlternate, CaseTransformAlternateLettercase, CaseTransformCapitalise,
@@ -24,17 +70,34 @@ var TransformTypes = []string{
 	CaseTransformSentence, CaseTransformUpper,
 }
 
+// A slice of available options for the characters words can contain
+var WordCharsets = []string{
+	WordCharsetAny, WordCharsetASCIILetters, WordCharsetCustom, WordCharsetLetters, WordCharsetLettersHyphen,
+}
+
 var PaddingCharacterOptions = append([]string{PaddingCharacterRandom}, DefaultSpecialCharacters...)
 
 var SeparatorCharacterOptions = append([]string{SeparatorCharacterRandom}, DefaultSpecialCharacters...)
 
-// A slice of available word lists
+// A slice of available options for how random separators are drawn
+var SeparatorModes = []string{SeparatorModePerGap, SeparatorModeSingle}
+
+// A slice of the built-in word lists
+//
+// Deprecated: Use asset.WordLists.Names, which also lists the word lists
+// registered at runtime.
 var WordLists = []string{
 	WordList40k, WordListAll, WordListDoctorWho, WordListEN, WordListENSmall,
 	WordListGameOfThrones, WordListHarryPotter, WordListMiddleEarth,
 	WordListPokemon, WordListStarTrek, WordListStarWars, WordListSunborn,
+	WordListEFFLarge, WordListEFFShort, WordListAdjectives, WordListAdverbs,
+	WordListNouns, WordListVerbs,
 }
 
+// The descriptions of the built-in word lists
+//
+// Deprecated: Use asset.WordLists.Description, which also describes the word
+// lists registered at runtime.
 var WordListDescriptionMap = map[string]string{
 	WordList40k:           "A Warhammer 40k word list (8600+ words)",
 	WordListAll:           "A combination of all the word lists (60000+ words)",
@@ -48,12 +111,23 @@ var WordListDescriptionMap = map[string]string{
 	WordListStarTrek:      "A Star Trek word list (8000+ words)",
 	WordListStarWars:      "A Star Wars word list (12100+ words)",
 	WordListSunborn:       "A Sunborn word list (31300+ words)",
+	WordListEFFLarge:      "The EFF large diceware word list, in dice roll order, 5 rolls per word (7776 words)",
+	WordListEFFShort:      "The EFF short diceware word list with unique three letter prefixes, in dice roll order, 4 rolls per word (1296 words)",
+	WordListAdjectives:    "A list of common English adjectives, for the adjective slots of grammatical passphrases (480+ words)",
+	WordListAdverbs:       "A list of common English adverbs, for the adverb slots of grammatical passphrases (190+ words)",
+	WordListNouns:         "A list of common English nouns, mostly animals and things, for the noun slots of grammatical passphrases (410+ words)",
+	WordListVerbs:         "A list of common English verbs in the third person singular, for the verb slots of grammatical passphrases (350+ words)",
 }
 
+// The descriptions of the built-in presets
+//
+// Deprecated: Use asset.Presets.Description, which also describes the presets
+// registered at runtime.
 var PresetDescriptionMap = map[string]string{
 	PresetAppleID:       "A preset respecting the many prerequisites Apple places on Apple ID passwords. The preset also limits itself to symbols found on the iOS letter and number keyboards (i.e. not the awkward to reach symbol keyboard)",
 	PresetDefault:       "The default preset resulting in a password consisting of 3 random words of between 4 and 8 letters with alternating case separated by a random character, with two random digits before and after, and padded with two random characters front and back",
 	PresetNTLM:          "A preset for 14 character Windows NTLMv1 password. WARNING - only use this preset if you have to, it is too short to be acceptably secure",
+	PresetRandom32:      "A preset for 32 character passwords of random letters, digits and symbols, with at least one of each, for service accounts and database users which need opaque strings rather than passphrases",
 	PresetSecurityQ:     "A preset for creating fake answers to security questions",
 	PresetWeb16:         "A preset for websites that insist passwords not be longer than 16 characters",
 	PresetWeb16XKPasswd: "A preset for websites that insist passwords not be longer than 16 characters, the same as the one found on xkpasswd.net.",
@@ -0,0 +1,241 @@
+package passwordrules
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+// The character classes of the CHARACTERS generator and the passwordrules
+// class holding the same characters
+var characterClasses = map[string]string{
+	option.CharacterClassDigits: ClassDigit,
+	option.CharacterClassLower:  ClassLower,
+	option.CharacterClassUpper:  ClassUpper,
+}
+
+// Apply changes the settings so the passwords they generate follow the
+// rules. The rules become the policy setting, tightening any policy already
+// set, so every password is checked against them. To draw fewer passwords
+// which break them:
+//   - symbol_alphabet and separator_alphabet are restricted to the allowed
+//     characters, and the separator and padding symbols are removed when
+//     none of theirs are allowed
+//   - case_transform is set to LOWER or UPPER when only one case is allowed,
+//     and the padding digits are removed when digits aren't allowed
+//   - length is brought within minlength and maxlength, as is pad_to_length
+//     with ADAPTIVE padding
+//   - the character_classes which aren't allowed are removed and the required
+//     ones get a minimum of 1
+func (r *Rules) Apply(cfg *config.Settings) {
+	cfg.Policy = r.policy(cfg.Policy)
+
+	allowed := r.allowed()
+	if allowed != nil {
+		restrictCharacters(cfg, allowed)
+	}
+
+	if r.MinLength > 0 {
+		cfg.Length = max(cfg.Length, r.MinLength)
+	}
+
+	if r.MaxLength > 0 {
+		cfg.Length = min(cfg.Length, r.MaxLength)
+	}
+
+	if cfg.PaddingType == option.PaddingTypeAdaptive {
+		cfg.PadToLength = max(cfg.PadToLength, r.MinLength)
+		if r.MaxLength > 0 {
+			cfg.PadToLength = min(cfg.PadToLength, r.MaxLength)
+		}
+	}
+
+	if len(cfg.CharacterClasses) > 0 {
+		cfg.CharacterClasses = r.characterClasses(cfg, allowed)
+	}
+}
+
+// Returns the characters passwords can contain: those of the allowed and
+// required properties. It returns nil if neither is set, or either allows
+// any character, as the settings are then left unrestricted.
+func (r *Rules) allowed() *CharacterSet {
+	if r.Allowed == nil && len(r.Required) == 0 {
+		return nil
+	}
+
+	sets := slices.Clone(r.Required)
+	if r.Allowed != nil {
+		sets = append(sets, *r.Allowed)
+	}
+
+	all := &CharacterSet{}
+	for _, cs := range sets {
+		if cs.isAny() {
+			return nil
+		}
+		all.Classes = append(all.Classes, cs.Classes...)
+		all.Characters += cs.Characters
+	}
+
+	return all
+}
+
+// Returns a copy of the policy, or a new policy if it is nil, tightened by the
+// rules.
+func (r *Rules) policy(p *config.Policy) *config.Policy {
+	next := &config.Policy{}
+	if p != nil {
+		*next = *p
+		next.Required = slices.Clone(p.Required)
+	}
+
+	next.MinLength = max(next.MinLength, r.MinLength)
+	next.MaxLength = stricterMaximum(next.MaxLength, r.MaxLength)
+	next.MaxRepeatedCharacters = stricterMaximum(next.MaxRepeatedCharacters, r.MaxConsecutive)
+
+	for _, cs := range r.Required {
+		if req, ok := requirement(cs); ok {
+			next.Required = append(next.Required, req)
+		}
+	}
+
+	if allowed := r.allowed(); allowed != nil {
+		for c := ' '; c <= '~'; c++ {
+			if !allowed.Contains(c) && !strings.ContainsRune(next.ForbiddenCharacters, c) {
+				next.ForbiddenCharacters += string(c)
+			}
+		}
+	}
+
+	return next
+}
+
+// Returns the smaller of two maximums, where 0 is no maximum.
+func stricterMaximum(a int, b int) int {
+	if a == 0 || b == 0 {
+		return max(a, b)
+	}
+
+	return min(a, b)
+}
+
+// Returns the policy requirement of a required character set: the matching
+// character class when it is a single named class, and its characters
+// otherwise. It returns false if the set allows any character, so requiring
+// it adds nothing.
+func requirement(cs CharacterSet) (config.PolicyRequirement, bool) {
+	if cs.isAny() {
+		return config.PolicyRequirement{}, false
+	}
+
+	if len(cs.Classes) == 1 && cs.Characters == "" {
+		for class, name := range characterClasses {
+			if cs.Classes[0] == name {
+				return config.PolicyRequirement{Class: class, Count: 1}, true
+			}
+		}
+	}
+
+	var chars strings.Builder
+	for c := ' '; c <= '~'; c++ {
+		if cs.Contains(c) {
+			chars.WriteRune(c)
+		}
+	}
+
+	for _, c := range cs.Characters {
+		if c > '~' {
+			chars.WriteRune(c)
+		}
+	}
+
+	return config.PolicyRequirement{Class: option.CharacterClassCustom, Characters: chars.String(), Count: 1}, true
+}
+
+// Restricts the alphabets, case and padding digits of the settings to the
+// allowed characters.
+func restrictCharacters(cfg *config.Settings, allowed *CharacterSet) {
+	keep := func(s string) bool {
+		for _, c := range s {
+			if !allowed.Contains(c) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	cfg.SymbolAlphabet = slices.DeleteFunc(slices.Clone(cfg.SymbolAlphabet), func(s string) bool { return !keep(s) })
+	cfg.SeparatorAlphabet = slices.DeleteFunc(slices.Clone(cfg.SeparatorAlphabet), func(s string) bool { return !keep(s) })
+	restrictSeparatorAndPadding(cfg, keep)
+
+	lower, upper := keep("abcdefghijklmnopqrstuvwxyz"), keep("ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	switch {
+	case lower && !upper:
+		cfg.CaseTransform = option.CaseTransformLower
+	case upper && !lower:
+		cfg.CaseTransform = option.CaseTransformUpper
+	}
+
+	if !keep("0123456789") {
+		cfg.PaddingDigitsBefore = 0
+		cfg.PaddingDigitsAfter = 0
+	}
+}
+
+// Removes the separator when none of its characters are allowed, and the
+// padding symbols likewise, so a generator can still be built from the
+// settings when the rules allow no symbols.
+func restrictSeparatorAndPadding(cfg *config.Settings, keep func(string) bool) {
+	random := cfg.SeparatorCharacter == option.SeparatorCharacterRandom
+	if (random && len(cfg.SeparatorAlphabet) == 0) || (!random && !keep(cfg.SeparatorCharacter)) {
+		cfg.SeparatorCharacter = ""
+		cfg.SeparatorMode = ""
+	}
+
+	random = cfg.PaddingCharacter == option.PaddingCharacterRandom
+	if (random && len(cfg.SymbolAlphabet) == 0) || (!random && !keep(cfg.PaddingCharacter)) {
+		cfg.PaddingCharacter = ""
+		cfg.PaddingType = option.PaddingTypeNone
+	}
+}
+
+// Returns the character classes of the CHARACTERS generator with those which
+// aren't allowed removed and those which are required given a minimum of at
+// least 1. SYMBOLS is removed when no symbols are allowed.
+func (r *Rules) characterClasses(cfg *config.Settings, allowed *CharacterSet) map[string]int {
+	next := maps.Clone(cfg.CharacterClasses)
+	for class, name := range characterClasses {
+		if allowed != nil && !isSubset(name, allowed) {
+			delete(next, class)
+		}
+	}
+
+	if len(cfg.SymbolAlphabet) == 0 {
+		delete(next, option.CharacterClassSymbols)
+	}
+
+	for _, cs := range r.Required {
+		req, ok := requirement(cs)
+		if !ok || req.Class == option.CharacterClassCustom {
+			continue
+		}
+		next[req.Class] = max(next[req.Class], 1)
+	}
+
+	return next
+}
+
+// Reports whether every character of the named class is in the set.
+func isSubset(class string, cs *CharacterSet) bool {
+	for c := ' '; c <= '~'; c++ {
+		if inClass(class, c) && !cs.Contains(c) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,240 @@
+package passwordrules
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+	"github.com/eljamo/libpass/v8/service"
+	"github.com/google/go-cmp/cmp"
+)
+
+func applyRules(t *testing.T, rules string, cfg *config.Settings) {
+	t.Helper()
+
+	r, err := Parse(rules)
+	if err != nil {
+		t.Fatalf("Parse(%q) error = %v", rules, err)
+	}
+
+	r.Apply(cfg)
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.DefaultSettings()
+	applyRules(t, "required: upper; required: digit; allowed: [-().&@?'#,/&quot;+]; max-consecutive: 2; minlength: 20;", cfg)
+
+	wantAlphabet := []string{"@", "&", "-", "+", "?", "/", "."}
+	if !slices.Equal(cfg.SymbolAlphabet, wantAlphabet) {
+		t.Errorf("SymbolAlphabet = %v, want %v", cfg.SymbolAlphabet, wantAlphabet)
+	}
+
+	if !slices.Equal(cfg.SeparatorAlphabet, wantAlphabet) {
+		t.Errorf("SeparatorAlphabet = %v, want %v", cfg.SeparatorAlphabet, wantAlphabet)
+	}
+
+	// Lower case letters are neither required nor allowed
+	if cfg.CaseTransform != option.CaseTransformUpper {
+		t.Errorf("CaseTransform = %s, want %s", cfg.CaseTransform, option.CaseTransformUpper)
+	}
+
+	if !slices.Equal(option.DefaultSpecialCharacters, config.DefaultSettings().SymbolAlphabet) {
+		t.Error("Apply() changed option.DefaultSpecialCharacters")
+	}
+
+	p := cfg.Policy
+	if p.MinLength != 20 || p.MaxLength != 0 || p.MaxRepeatedCharacters != 2 {
+		t.Errorf("Policy lengths = %d, %d, %d, want 20, 0, 2", p.MinLength, p.MaxLength, p.MaxRepeatedCharacters)
+	}
+
+	wantRequired := []config.PolicyRequirement{
+		{Class: option.CharacterClassUpper, Count: 1},
+		{Class: option.CharacterClassDigits, Count: 1},
+	}
+	if !cmp.Equal(p.Required, wantRequired) {
+		t.Errorf("Policy.Required = %+v, want %+v", p.Required, wantRequired)
+	}
+
+	for _, c := range "abz!$%;[ " {
+		if !strings.ContainsRune(p.ForbiddenCharacters, c) {
+			t.Errorf("Policy.ForbiddenCharacters = %q, want it to contain %q", p.ForbiddenCharacters, c)
+		}
+	}
+
+	for _, c := range "AZ09-\"+" {
+		if strings.ContainsRune(p.ForbiddenCharacters, c) {
+			t.Errorf("Policy.ForbiddenCharacters = %q, want it not to contain %q", p.ForbiddenCharacters, c)
+		}
+	}
+}
+
+func TestApplyAnyCharacterAllowed(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.DefaultSettings()
+	applyRules(t, "minlength: 8; maxlength: 63; required: lower; required: upper; required: digit; allowed: ascii-printable;", cfg)
+
+	if !slices.Equal(cfg.SymbolAlphabet, option.DefaultSpecialCharacters) {
+		t.Errorf("SymbolAlphabet = %v, want it unchanged", cfg.SymbolAlphabet)
+	}
+
+	if cfg.CaseTransform != option.CaseTransformRandom {
+		t.Errorf("CaseTransform = %s, want it unchanged", cfg.CaseTransform)
+	}
+
+	want := &config.Policy{
+		MaxLength: 63,
+		MinLength: 8,
+		Required: []config.PolicyRequirement{
+			{Class: option.CharacterClassLower, Count: 1},
+			{Class: option.CharacterClassUpper, Count: 1},
+			{Class: option.CharacterClassDigits, Count: 1},
+		},
+	}
+	if !cmp.Equal(cfg.Policy, want) {
+		t.Errorf("Policy = %+v, want %+v", cfg.Policy, want)
+	}
+}
+
+func TestApplyTightensPolicy(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.DefaultSettings()
+	cfg.Policy = &config.Policy{MinLength: 30, MaxLength: 50, ForbiddenCharacters: `\`}
+	cfg.PaddingType = option.PaddingTypeAdaptive
+	cfg.PadToLength = 48
+	applyRules(t, "maxlength: 40; required: [!@#], digit", cfg)
+
+	p := cfg.Policy
+	if p.MinLength != 30 || p.MaxLength != 40 {
+		t.Errorf("Policy lengths = %d, %d, want 30, 40", p.MinLength, p.MaxLength)
+	}
+
+	wantRequired := []config.PolicyRequirement{
+		{Class: option.CharacterClassCustom, Characters: "!#0123456789@", Count: 1},
+	}
+	if !cmp.Equal(p.Required, wantRequired) {
+		t.Errorf("Policy.Required = %+v, want %+v", p.Required, wantRequired)
+	}
+
+	// The allowed characters are the required ones, which forbids the rest
+	if !strings.HasPrefix(p.ForbiddenCharacters, `\`) || !strings.Contains(p.ForbiddenCharacters, "a") {
+		t.Errorf("Policy.ForbiddenCharacters = %q, want \\ and the characters which aren't allowed", p.ForbiddenCharacters)
+	}
+
+	if cfg.PadToLength != 40 {
+		t.Errorf("PadToLength = %d, want 40", cfg.PadToLength)
+	}
+}
+
+func TestApplyCharacterClasses(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.DefaultSettings()
+	cfg.Generator = option.GeneratorCharacters
+	cfg.Length = 32
+	cfg.CharacterClasses = map[string]int{
+		option.CharacterClassLower:   1,
+		option.CharacterClassUpper:   1,
+		option.CharacterClassDigits:  0,
+		option.CharacterClassSymbols: 1,
+	}
+	applyRules(t, "required: lower; required: digit; allowed: [-_]; minlength: 20; maxlength: 24", cfg)
+
+	if cfg.Length != 24 {
+		t.Errorf("Length = %d, want 24", cfg.Length)
+	}
+
+	want := map[string]int{
+		option.CharacterClassLower:   1,
+		option.CharacterClassDigits:  1,
+		option.CharacterClassSymbols: 1,
+	}
+	if !cmp.Equal(cfg.CharacterClasses, want) {
+		t.Errorf("CharacterClasses = %v, want %v", cfg.CharacterClasses, want)
+	}
+
+	if !slices.Equal(cfg.SymbolAlphabet, []string{"-"}) {
+		t.Errorf("SymbolAlphabet = %v, want [-]", cfg.SymbolAlphabet)
+	}
+}
+
+func TestApplyGenerate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		rules string
+		cfg   func() *config.Settings
+	}{
+		{
+			name:  "Words",
+			rules: "required: upper; required: digit; allowed: [-().&@?'#,/&quot;+]; max-consecutive: 2; minlength: 20;",
+			cfg:   config.DefaultSettings,
+		},
+		{
+			name:  "Characters",
+			rules: "required: lower; required: digit; allowed: [-_]; max-consecutive: 1; minlength: 20; maxlength: 24",
+			cfg: func() *config.Settings {
+				cfg := config.DefaultSettings()
+				cfg.Generator = option.GeneratorCharacters
+				cfg.Length = 32
+				cfg.CharacterClasses = map[string]int{option.CharacterClassLower: 1, option.CharacterClassSymbols: 1}
+
+				return cfg
+			},
+		},
+		{
+			name:  "Letters and digits only",
+			rules: "allowed: upper, lower, digit; minlength: 8",
+			cfg:   config.DefaultSettings,
+		},
+		{
+			name:  "Lower case letters only",
+			rules: "required: lower; maxlength: 16",
+			cfg: func() *config.Settings {
+				cfg := config.DefaultSettings()
+				cfg.NumWords = 2
+				cfg.WordLengthMax = 6
+				cfg.PaddingDigitsBefore = 0
+				cfg.PaddingDigitsAfter = 0
+
+				return cfg
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := tt.cfg()
+			applyRules(t, tt.rules, cfg)
+
+			svc, err := service.NewGeneratorServiceWithRNG(cfg, service.NewSeededRNGService([]byte("passwordrules")))
+			if err != nil {
+				t.Fatalf("NewGeneratorServiceWithRNG() error = %v", err)
+			}
+
+			pws, err := svc.Generate()
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			for _, pw := range pws {
+				if n := utf8.RuneCountInString(pw); n < cfg.Policy.MinLength {
+					t.Errorf("Generate() password %q is %d runes, want at least %d", pw, n, cfg.Policy.MinLength)
+				}
+
+				if strings.ContainsAny(pw, cfg.Policy.ForbiddenCharacters) {
+					t.Errorf("Generate() password %q contains a forbidden character", pw)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package passwordrules
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Property names of the passwordrules syntax
+const (
+	PropertyAllowed        string = "allowed"
+	PropertyMaxConsecutive string = "max-consecutive"
+	PropertyMaxLength      string = "maxlength"
+	PropertyMinLength      string = "minlength"
+	PropertyRequired       string = "required"
+)
+
+// Named character classes of the passwordrules syntax
+const (
+	ClassASCIIPrintable string = "ascii-printable"
+	ClassDigit          string = "digit"
+	ClassLower          string = "lower"
+	ClassSpecial        string = "special"
+	ClassUnicode        string = "unicode"
+	ClassUpper          string = "upper"
+)
+
+// The named character classes
+var classes = []string{ClassASCIIPrintable, ClassDigit, ClassLower, ClassSpecial, ClassUnicode, ClassUpper}
+
+// The characters of the special class: the printable ASCII characters which
+// aren't letters or digits, and space
+const specialCharacters string = " !\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+// CharacterSet is a set of characters given by the value of a required or
+// allowed property, the union of its named classes and its custom
+// characters, e.g. upper, [-().&@].
+type CharacterSet struct {
+	// The named character classes
+	Classes []string
+	// The characters of the custom character classes, in [brackets]
+	Characters string
+}
+
+// Contains reports whether the rune is in the character set.
+func (cs CharacterSet) Contains(r rune) bool {
+	if strings.ContainsRune(cs.Characters, r) {
+		return true
+	}
+
+	for _, c := range cs.Classes {
+		if inClass(c, r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reports whether the set contains every character, so requiring it adds
+// nothing.
+func (cs CharacterSet) isAny() bool {
+	return slices.Contains(cs.Classes, ClassASCIIPrintable) || slices.Contains(cs.Classes, ClassUnicode)
+}
+
+// Reports whether the rune is in the named character class.
+func inClass(class string, r rune) bool {
+	switch class {
+	case ClassUpper:
+		return r >= 'A' && r <= 'Z'
+	case ClassLower:
+		return r >= 'a' && r <= 'z'
+	case ClassDigit:
+		return r >= '0' && r <= '9'
+	case ClassSpecial:
+		return strings.ContainsRune(specialCharacters, r)
+	case ClassASCIIPrintable:
+		return r >= ' ' && r <= '~'
+	}
+
+	return unicode.IsPrint(r)
+}
+
+// Rules holds the rules of a passwordrules attribute, the format WebKit and
+// Apple use for sites to publish what their passwords must look like, e.g.
+// "required: upper; required: digit; allowed: [-().&@]; max-consecutive: 2;
+// minlength: 20;". A zero value rule isn't set.
+type Rules struct {
+	// The characters passwords can contain besides the required ones, nil
+	// when there is no allowed property
+	Allowed *CharacterSet
+	// The maximum number of times the same character can appear in a row
+	MaxConsecutive int
+	// The maximum number of characters in a password
+	MaxLength int
+	// The minimum number of characters in a password
+	MinLength int
+	// The character sets a password must contain a character from, one per
+	// required property
+	Required []CharacterSet
+}
+
+// ParseFile parses the passwordrules in the file at the given path. It
+// returns an error if the file cannot be read or the rules are invalid.
+func ParseFile(filePath string) (*Rules, error) {
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passwordrules file: %w", err)
+	}
+
+	return Parse(string(b))
+}
+
+// Parse parses a passwordrules attribute value, properties separated by
+// semicolons. HTML character references are unescaped first, so rules can be
+// copied verbatim from a page, e.g. &quot; for a double quote. Property names
+// are case insensitive and unknown properties are ignored, as the syntax
+// requires. When a length property is given more than once the strictest
+// value is kept, and allowed properties are merged. It returns an error if a
+// property is malformed.
+func Parse(rules string) (*Rules, error) {
+	r := &Rules{}
+	for _, prop := range splitOutsideBrackets(html.UnescapeString(rules), ';') {
+		if strings.TrimSpace(prop) == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(prop, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid passwordrules property %q, want name: value", strings.TrimSpace(prop))
+		}
+
+		if err := r.setProperty(strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(value)); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Sets the property with the given name from its value.
+func (r *Rules) setProperty(name string, value string) error {
+	switch name {
+	case PropertyRequired, PropertyAllowed:
+		cs, err := parseCharacterSet(name, value)
+		if err != nil {
+			return err
+		}
+
+		if name == PropertyRequired {
+			r.Required = append(r.Required, cs)
+			return nil
+		}
+
+		if r.Allowed == nil {
+			r.Allowed = &CharacterSet{}
+		}
+		r.Allowed.Classes = append(r.Allowed.Classes, cs.Classes...)
+		r.Allowed.Characters += cs.Characters
+	case PropertyMinLength, PropertyMaxLength, PropertyMaxConsecutive:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid passwordrules %s value (%s), want a positive integer", name, value)
+		}
+
+		switch name {
+		case PropertyMinLength:
+			r.MinLength = max(r.MinLength, n)
+		case PropertyMaxLength:
+			r.MaxLength = stricterMaximum(r.MaxLength, n)
+		default:
+			r.MaxConsecutive = stricterMaximum(r.MaxConsecutive, n)
+		}
+	}
+
+	return nil
+}
+
+// Parses a comma separated list of named classes and custom classes in
+// brackets. It returns an error if a class is unknown or a bracket isn't
+// closed.
+func parseCharacterSet(name string, value string) (CharacterSet, error) {
+	var cs CharacterSet
+	for _, item := range splitOutsideBrackets(value, ',') {
+		item = strings.TrimSpace(item)
+		switch {
+		case strings.HasPrefix(item, "["):
+			if len(item) < 2 || !strings.HasSuffix(item, "]") {
+				return CharacterSet{}, fmt.Errorf("invalid passwordrules %s class %s, want a closing ]", name, item)
+			}
+
+			for _, r := range item[1 : len(item)-1] {
+				if !strings.ContainsRune(cs.Characters, r) {
+					cs.Characters += string(r)
+				}
+			}
+		case slices.Contains(classes, strings.ToLower(item)):
+			cs.Classes = append(cs.Classes, strings.ToLower(item))
+		default:
+			return CharacterSet{}, fmt.Errorf("invalid passwordrules %s class (%s)", name, item)
+		}
+	}
+
+	return cs, nil
+}
+
+// Splits the string at each separator which isn't inside a custom class in
+// brackets. A ] followed by another ] is a character of the class, so a class
+// can contain ], e.g. [-]].
+func splitOutsideBrackets(s string, sep rune) []string {
+	var parts []string
+	var sb strings.Builder
+	inBrackets := false
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case inBrackets && r == ']':
+			inBrackets = i+1 < len(runes) && runes[i+1] == ']'
+		case !inBrackets && r == '[':
+			inBrackets = true
+		case !inBrackets && r == sep:
+			parts = append(parts, sb.String())
+			sb.Reset()
+			continue
+		}
+		sb.WriteRune(r)
+	}
+
+	return append(parts, sb.String())
+}
@@ -0,0 +1,143 @@
+package passwordrules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    *Rules
+		wantErr bool
+	}{
+		{
+			name:  "Published rules with an HTML reference",
+			input: "required: upper; required: digit; allowed: [-().&@?'#,/&quot;+]; max-consecutive: 2; minlength: 20;",
+			want: &Rules{
+				Allowed:        &CharacterSet{Characters: `-().&@?'#,/"+`},
+				MaxConsecutive: 2,
+				MinLength:      20,
+				Required:       []CharacterSet{{Classes: []string{ClassUpper}}, {Classes: []string{ClassDigit}}},
+			},
+		},
+		{
+			name:  "Classes listed together",
+			input: "REQUIRED: lower, upper; allowed: digit, [!@]; allowed: [#]; maxlength: 32",
+			want: &Rules{
+				Allowed:   &CharacterSet{Classes: []string{ClassDigit}, Characters: "!@#"},
+				MaxLength: 32,
+				Required:  []CharacterSet{{Classes: []string{ClassLower, ClassUpper}}},
+			},
+		},
+		{
+			name:  "Separators and brackets in a custom class",
+			input: "allowed: [;,[]]; minlength: 8",
+			want: &Rules{
+				Allowed:   &CharacterSet{Characters: ";,[]"},
+				MinLength: 8,
+			},
+		},
+		{
+			name:  "Strictest lengths kept",
+			input: "minlength: 8; minlength: 12; maxlength: 64; maxlength: 40; max-consecutive: 3; max-consecutive: 4",
+			want:  &Rules{MinLength: 12, MaxLength: 40, MaxConsecutive: 3},
+		},
+		{
+			name:  "Unknown properties ignored",
+			input: "minlength: 8; colour: blue;",
+			want:  &Rules{MinLength: 8},
+		},
+		{
+			name:  "Empty",
+			input: "",
+			want:  &Rules{},
+		},
+		{
+			name:    "Missing colon",
+			input:   "minlength 8",
+			wantErr: true,
+		},
+		{
+			name:    "Unknown class",
+			input:   "required: emoji",
+			wantErr: true,
+		},
+		{
+			name:    "Unclosed bracket",
+			input:   "allowed: [abc",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid length",
+			input:   "minlength: eight",
+			wantErr: true,
+		},
+		{
+			name:    "Zero length",
+			input:   "maxlength: 0",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Parse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !cmp.Equal(got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v\nDiff: %s", tt.input, got, tt.want, cmp.Diff(got, tt.want))
+			}
+		})
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "example.com.txt")
+	if err := os.WriteFile(path, []byte("minlength: 12;\nrequired: digit;\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	got, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	want := &Rules{MinLength: 12, Required: []CharacterSet{{Classes: []string{ClassDigit}}}}
+	if !cmp.Equal(got, want) {
+		t.Errorf("ParseFile() = %+v, want %+v", got, want)
+	}
+
+	if _, err := ParseFile(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("ParseFile() error = nil, want an error for a missing file")
+	}
+}
+
+func TestCharacterSetContains(t *testing.T) {
+	t.Parallel()
+
+	cs := CharacterSet{Classes: []string{ClassUpper, ClassSpecial}, Characters: "é"}
+	for _, r := range "AZ !~é" {
+		if !cs.Contains(r) {
+			t.Errorf("Contains(%q) = false, want true", r)
+		}
+	}
+
+	for _, r := range "az09ü" {
+		if cs.Contains(r) {
+			t.Errorf("Contains(%q) = true, want false", r)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package config
+
+// Policy holds the rules a site or system sets for its passwords, such as
+// "12 to 24 characters with an uppercase letter, a digit and one of !@#".
+// Generators draw a password again while it breaks a rule, so every password
+// they return follows the policy. A zero value rule isn't checked. The entropy
+// generators report is that of the passwords before any are rejected, each
+// halving of the share which follow the policy costs a bit.
+type Policy struct {
+	// The characters a password cannot contain
+	ForbiddenCharacters string `key:"forbidden_characters" json:"forbidden_characters,omitempty"`
+	// The maximum number of characters in a password
+	MaxLength int `key:"max_length" json:"max_length,omitempty"`
+	// The maximum number of times the same character can appear in a row
+	MaxRepeatedCharacters int `key:"max_repeated_characters" json:"max_repeated_characters,omitempty"`
+	// The minimum number of characters in a password
+	MinLength int `key:"min_length" json:"min_length,omitempty"`
+	// Whether a password must start with a letter
+	MustStartWithLetter bool `key:"must_start_with_letter" json:"must_start_with_letter,omitempty"`
+	// The character classes a password must contain, and how many characters from each
+	Required []PolicyRequirement `key:"required" json:"required,omitempty"`
+}
+
+// PolicyRequirement is a character class a password must contain a minimum
+// number of characters from.
+type PolicyRequirement struct {
+	// The characters of the class when class is CUSTOM
+	Characters string `key:"characters" json:"characters,omitempty"`
+	// The character class, LOWER, UPPER, DIGITS, SYMBOLS or CUSTOM
+	Class string `key:"class" json:"class,omitempty"`
+	// The minimum number of characters from the class
+	Count int `key:"count" json:"count,omitempty"`
+}
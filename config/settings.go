@@ -11,8 +11,34 @@ import (
 )
 
 type Settings struct {
+	// Whether to leave characters which are easily mistaken for one another out of the words, alphabets and digits
+	AvoidAmbiguous bool `key:"avoid_ambiguous" json:"avoid_ambiguous,omitempty"`
+	// Words to exclude from the word list, compared case-insensitively, and which adjacent words cannot form across the separator between them
+	Blocklist []string `key:"blocklist" json:"blocklist,omitempty"`
+	// Whether to also exclude the words in the embedded default blocklist of offensive words
+	BlocklistDefault bool `key:"blocklist_default" json:"blocklist_default,omitempty"`
+	// The path of a file of words to exclude from the word list, one word per line
+	BlocklistFile string `key:"blocklist_file" json:"blocklist_file,omitempty"`
+	// Regular expressions matching words to exclude from the word list, and which two adjacent words joined together cannot match
+	BlocklistPatterns []string `key:"blocklist_patterns" json:"blocklist_patterns,omitempty"`
 	// The type of case transformation to apply to the words
 	CaseTransform string `key:"case_transform" json:"case_transform,omitempty"`
+	// The character classes to draw characters from and the minimum number of characters from each, when generator is CHARACTERS
+	CharacterClasses map[string]int `key:"character_classes" json:"character_classes,omitempty"`
+	// Whether each character_substitutions character is replaced ALWAYS (the default), at RANDOM, or at random with AT_LEAST_ONE replaced in each password
+	CharacterSubstitutionMode string `key:"character_substitution_mode" json:"character_substitution_mode,omitempty"`
+	// The characters of the words to replace once the case transformation is applied, matched case-insensitively, and what to replace them with, e.g. {"a": "@", "o": "0"}, when pattern isn't set and generator is WORDS, PRONOUNCEABLE or GRAMMATICAL
+	CharacterSubstitutions map[string]string `key:"character_substitutions" json:"character_substitutions,omitempty"`
+	// The characters of the CUSTOM character class
+	CustomCharacters string `key:"custom_characters" json:"custom_characters,omitempty"`
+	// The kind of password to generate, passphrases of words or strings of random characters
+	Generator string `key:"generator" json:"generator,omitempty"`
+	// The part of speech of each word when generator is GRAMMATICAL, one per word
+	Grammar []string `key:"grammar" json:"grammar,omitempty"`
+	// The number of characters in the password when generator is CHARACTERS
+	Length int `key:"length" json:"length,omitempty"`
+	// The minimum seen entropy in bits a generator must provide, 0 disables the check, which ignores the passwords policy rejects
+	MinEntropyBits int `key:"min_entropy_bits" json:"min_entropy_bits,omitempty"`
 	// The number of passwords to generate
 	NumPasswords int `key:"num_passwords" json:"num_passwords,omitempty"`
 	// The number of words to use in the password
@@ -27,24 +53,48 @@ type Settings struct {
 	PaddingDigitsAfter int `key:"padding_digits_after" json:"padding_digits_after,omitempty"`
 	// The number of padding digits to add before the password
 	PaddingDigitsBefore int `key:"padding_digits_before" json:"padding_digits_before,omitempty"`
+	// What ADAPTIVE padding does with a password longer than pad_to_length, TRUNCATE it (the default) or REJECT it and generate another
+	PaddingOverflow string `key:"padding_overflow" json:"padding_overflow,omitempty"`
+	// Where ADAPTIVE padding adds its characters, AFTER (the default), BEFORE or BOTH
+	PaddingPosition string `key:"padding_position" json:"padding_position,omitempty"`
 	// The type of padding to apply to the password
 	PaddingType string `key:"padding_type" json:"padding_type,omitempty"`
-	// The length to pad the password to
+	// The length to pad the password to, or truncate it to, with ADAPTIVE padding
 	PadToLength int `key:"pad_to_length" json:"pad_to_length,omitempty"`
+	// The layout of the password, W for a capitalised word, w for a lower case word, d for a digit, s for a symbol, and any other character, or one escaped with a backslash, verbatim
+	Pattern string `key:"pattern" json:"pattern,omitempty"`
+	// The rules every password must follow, passwords breaking them are generated again
+	Policy *Policy `key:"policy" json:"policy,omitempty"`
 	// The preset to use for generating the password
 	Preset string `key:"preset" json:"preset,omitempty"`
-	// The alphabet to use for the separator character when using a random character
+	// The alphabet to use for the separator character when using a random character, elements can be several characters long
 	SeparatorAlphabet []string `key:"separator_alphabet" json:"separator_alphabet,omitempty"`
-	// The character to use to separate the words
+	// The character or characters to use to separate the words
 	SeparatorCharacter string `key:"separator_character" json:"separator_character,omitempty"`
+	// Whether a random separator is drawn once per password (SINGLE, the default) or for each gap (PER_GAP)
+	SeparatorMode string `key:"separator_mode" json:"separator_mode,omitempty"`
 	// The alphabet to use for the symbol padding character when random
 	SymbolAlphabet []string `key:"symbol_alphabet" json:"symbol_alphabet,omitempty"`
+	// The number of leading letters two words must share to count as duplicates when unique_words is set, 0 compares whole words
+	UniqueWordPrefixLength int `key:"unique_word_prefix_length" json:"unique_word_prefix_length,omitempty"`
+	// Whether to draw words without replacement, so no word is repeated in a password
+	UniqueWords bool `key:"unique_words" json:"unique_words,omitempty"`
+	// The characters words must be made of, words with any other character are left out of the word list
+	WordCharset string `key:"word_charset" json:"word_charset,omitempty"`
+	// The regular expression character class words must be made of when word_charset is CUSTOM
+	WordCharsetClass string `key:"word_charset_class" json:"word_charset_class,omitempty"`
 	// The maximum length of a word to use in the password
 	WordLengthMax int `key:"word_length_max" json:"word_length_max,omitempty"`
 	// The minimum length of a word to use in the password
 	WordLengthMin int `key:"word_length_min" json:"word_length_min,omitempty"`
-	// The word list to use for generating the password
+	// The word list to use for generating the password, set word_list_weights or word_list_slots to draw from several
 	WordList string `key:"word_list" json:"word_list,omitempty"`
+	// The path of a file to read the word list from, one word per line, used instead of word_list
+	WordListFile string `key:"word_list_file" json:"word_list_file,omitempty"`
+	// The word list to draw each word from in turn, one per word, used instead of word_list
+	WordListSlots []string `key:"word_list_slots" json:"word_list_slots,omitempty"`
+	// The word lists to draw words from and their relative weights, used instead of word_list
+	WordListWeights map[string]int `key:"word_list_weights" json:"word_list_weights,omitempty"`
 }
 
 const (
@@ -170,7 +170,7 @@ func TestNewDoesNotMutateDefaultSpecialCharacters(t *testing.T) {
 func TestNewAcceptsAllEmbeddedPresets(t *testing.T) {
 	t.Parallel()
 
-	for _, preset := range option.Presets {
+	for _, preset := range asset.Presets.Names() {
 		t.Run(preset, func(t *testing.T) {
 			t.Parallel()
 			m, err := asset.GetJSONPreset(preset)
@@ -247,26 +247,84 @@ func TestJsonToSettings(t *testing.T) {
 				"num_passwords": 5
 			}`),
 			want: &Settings{
-				CaseTransform:           "upper",
-				NumPasswords:            5,
-				NumWords:                0,
-				PaddingCharacter:        "",
-				PaddingCharactersAfter:  0,
-				PaddingCharactersBefore: 0,
-				PaddingDigitsAfter:      0,
-				PaddingDigitsBefore:     0,
-				PaddingType:             "",
-				PadToLength:             0,
-				Preset:                  "",
-				SeparatorAlphabet:       nil,
-				SeparatorCharacter:      "",
-				SymbolAlphabet:          nil,
-				WordLengthMax:           0,
-				WordLengthMin:           0,
-				WordList:                "",
+				AvoidAmbiguous:            false,
+				Blocklist:                 nil,
+				BlocklistDefault:          false,
+				BlocklistFile:             "",
+				BlocklistPatterns:         nil,
+				CaseTransform:             "upper",
+				CharacterClasses:          nil,
+				CharacterSubstitutionMode: "",
+				CharacterSubstitutions:    nil,
+				CustomCharacters:          "",
+				Generator:                 "",
+				Grammar:                   nil,
+				Length:                    0,
+				MinEntropyBits:            0,
+				NumPasswords:              5,
+				NumWords:                  0,
+				PaddingCharacter:          "",
+				PaddingCharactersAfter:    0,
+				PaddingCharactersBefore:   0,
+				PaddingDigitsAfter:        0,
+				PaddingDigitsBefore:       0,
+				PaddingOverflow:           "",
+				PaddingPosition:           "",
+				PaddingType:               "",
+				PadToLength:               0,
+				Pattern:                   "",
+				Policy:                    nil,
+				Preset:                    "",
+				SeparatorAlphabet:         nil,
+				SeparatorCharacter:        "",
+				SeparatorMode:             "",
+				SymbolAlphabet:            nil,
+				UniqueWordPrefixLength:    0,
+				UniqueWords:               false,
+				WordCharset:               "",
+				WordCharsetClass:          "",
+				WordLengthMax:             0,
+				WordLengthMin:             0,
+				WordList:                  "",
+				WordListFile:              "",
+				WordListSlots:             nil,
+				WordListWeights:           nil,
 			},
 			wantErr: false,
 		},
+		{
+			name: "Policy",
+			input: []byte(`{
+				"policy": {
+					"min_length": 12,
+					"max_length": 24,
+					"required": [{"class": "UPPER", "count": 1}, {"class": "CUSTOM", "characters": "!@#", "count": 1}],
+					"forbidden_characters": "\\\"",
+					"max_repeated_characters": 2,
+					"must_start_with_letter": true
+				}
+			}`),
+			want: &Settings{
+				Policy: &Policy{
+					ForbiddenCharacters:   `\"`,
+					MaxLength:             24,
+					MaxRepeatedCharacters: 2,
+					MinLength:             12,
+					MustStartWithLetter:   true,
+					Required: []PolicyRequirement{
+						{Class: option.CharacterClassUpper, Count: 1},
+						{Class: option.CharacterClassCustom, Characters: "!@#", Count: 1},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Unknown policy key",
+			input:   []byte(`{"policy": {"min_lenght": 12}}`),
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name:    "Invalid JSON",
 			input:   []byte(`{"num_passwords": "invalid"}`),
@@ -13,7 +13,7 @@ func main() {
 		fmt.Println(err)
 	}
 
-	svc, err := service.NewPasswordGeneratorService(cfg)
+	svc, err := service.NewGeneratorService(cfg)
 	if err != nil {
 		fmt.Println(err)
 	}
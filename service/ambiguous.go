@@ -0,0 +1,131 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+const (
+	// The smallest digit used for padding when avoid_ambiguous is set, 0 and 1
+	// are mistaken for O and l
+	minUnambiguousDigit int = 2
+	// The number of digits used for padding when avoid_ambiguous is set
+	numUnambiguousDigits int = maxDigit - minUnambiguousDigit
+)
+
+// Reports whether the string contains a character or sequence of characters
+// which is easily mistaken for another when read.
+func isAmbiguous(s string) bool {
+	for _, c := range option.AmbiguousCharacters {
+		if strings.Contains(s, c) {
+			return true
+		}
+	}
+
+	for _, seq := range option.AmbiguousSequences {
+		if strings.Contains(s, seq) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reports whether any form the case transformation can give the word is
+// ambiguous, e.g. a word containing an i is ambiguous when it can be upper
+// cased to contain an I.
+func isAmbiguousWord(word string, caseTransform string) bool {
+	return slices.ContainsFunc(caseForms(word, caseTransform), isAmbiguous)
+}
+
+// Returns every form the case transformation can give the word, wherever it
+// is placed in the password.
+func caseForms(word string, caseTransform string) []string {
+	switch caseTransform {
+	case option.CaseTransformAlternate, option.CaseTransformRandom:
+		return []string{strings.ToLower(word), strings.ToUpper(word)}
+	case option.CaseTransformSentence:
+		// The first word is capitalised and the rest are lower case
+		t := &DefaultTransformerService{cfg: &config.Settings{CaseTransform: option.CaseTransformCapitalise}}
+
+		return []string{t.capitalise([]string{word})[0], strings.ToLower(word)}
+	}
+
+	// Every other transformation gives each word a single form, without
+	// drawing random numbers
+	t := &DefaultTransformerService{cfg: &config.Settings{CaseTransform: caseTransform}}
+	forms, err := t.Transform([]string{word})
+	if err != nil {
+		return []string{strings.ToLower(word), strings.ToUpper(word)}
+	}
+
+	return forms
+}
+
+// Returns the elements of the alphabet which aren't ambiguous when
+// avoid_ambiguous is set, and the alphabet otherwise.
+func effectiveAlphabet(cfg *config.Settings, alphabet []string) []string {
+	if !cfg.AvoidAmbiguous {
+		return alphabet
+	}
+
+	return slices.DeleteFunc(slices.Clone(alphabet), isAmbiguous)
+}
+
+// Returns the number of digits padding digits are drawn from.
+func numPaddingDigits(cfg *config.Settings) int {
+	if cfg.AvoidAmbiguous {
+		return numUnambiguousDigits
+	}
+
+	return maxDigit
+}
+
+// Generates a single random digit, from 2 to 9 when avoid_ambiguous is set as
+// 0 and 1 are mistaken for O and l.
+func generateDigit(cfg *config.Settings, rngSvc RNGService) (int, error) {
+	if !cfg.AvoidAmbiguous {
+		return rngSvc.GenerateDigit()
+	}
+
+	num, err := rngSvc.GenerateWithMax(numUnambiguousDigits)
+	if err != nil {
+		return 0, err
+	}
+
+	return minUnambiguousDigit + num, nil
+}
+
+// Checks a character setting, which is either a fixed character or random
+// from an alphabet, when avoid_ambiguous is set. It returns an error if the
+// fixed character is ambiguous or the alphabet has no unambiguous characters.
+func validateUnambiguous(
+	cfg *config.Settings,
+	charKey string,
+	char string,
+	random bool,
+	alphabetKey string,
+	alphabet []string,
+) error {
+	if !cfg.AvoidAmbiguous {
+		return nil
+	}
+
+	if !random {
+		if isAmbiguous(char) {
+			return fmt.Errorf("%s (%s) is ambiguous and %s is set", charKey, char, option.ConfigKeyAvoidAmbiguous)
+		}
+
+		return nil
+	}
+
+	if len(effectiveAlphabet(cfg, alphabet)) == 0 {
+		return fmt.Errorf("%s has no unambiguous characters and %s is set", alphabetKey, option.ConfigKeyAvoidAmbiguous)
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+func TestIsAmbiguousWord(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		word          string
+		caseTransform string
+		want          bool
+	}{
+		{name: "Plain word", word: "apple", caseTransform: option.CaseTransformNone, want: true},
+		{name: "Upper case leaves out l", word: "apple", caseTransform: option.CaseTransformUpper, want: false},
+		{name: "Lower case i", word: "pig", caseTransform: option.CaseTransformLower, want: false},
+		{name: "Upper case I", word: "pig", caseTransform: option.CaseTransformUpper, want: true},
+		{name: "Random case can give I", word: "pig", caseTransform: option.CaseTransformRandom, want: true},
+		{name: "Sentence case can give I", word: "ice", caseTransform: option.CaseTransformSentence, want: true},
+		{name: "Capitalise gives I only at the start", word: "pig", caseTransform: option.CaseTransformCapitalise, want: false},
+		{name: "Letters which read as m", word: "barn", caseTransform: option.CaseTransformLower, want: true},
+		{name: "Upper case O", word: "dog", caseTransform: option.CaseTransformUpper, want: true},
+		{name: "Unambiguous", word: "cat", caseTransform: option.CaseTransformRandom, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := isAmbiguousWord(tt.word, tt.caseTransform); got != tt.want {
+				t.Errorf("isAmbiguousWord(%q, %s) = %v, want %v", tt.word, tt.caseTransform, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEffectiveAlphabet(t *testing.T) {
+	t.Parallel()
+
+	alphabet := []string{"!", "|", "-", "l", "0", "."}
+
+	got := effectiveAlphabet(&config.Settings{AvoidAmbiguous: true}, alphabet)
+	if want := []string{"!", "-", "."}; !slices.Equal(got, want) {
+		t.Errorf("effectiveAlphabet() = %v, want %v", got, want)
+	}
+
+	if got := effectiveAlphabet(&config.Settings{}, alphabet); !slices.Equal(got, alphabet) {
+		t.Errorf("effectiveAlphabet() = %v, want %v", got, alphabet)
+	}
+
+	if len(alphabet) != 6 {
+		t.Errorf("effectiveAlphabet() modified the alphabet, len = %d", len(alphabet))
+	}
+}
+
+func TestAvoidAmbiguousServices(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Separator", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := &config.Settings{
+			AvoidAmbiguous: true, SeparatorCharacter: option.SeparatorCharacterRandom,
+			SeparatorAlphabet: []string{"|", "-", "l", "."},
+		}
+
+		svc, err := NewSeparatorService(cfg, &mockRNGService{})
+		if err != nil {
+			t.Fatalf("NewSeparatorService() error = %v", err)
+		}
+
+		got, err := svc.Separate([]string{"cat", "dog"})
+		if err != nil {
+			t.Fatalf("Separate() error = %v", err)
+		}
+
+		if want := []string{".", "cat", ".", "dog", "."}; !slices.Equal(got, want) {
+			t.Errorf("Separate() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Padding digits", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := &config.Settings{AvoidAmbiguous: true, PaddingType: option.PaddingTypeNone}
+
+		svc, err := NewPaddingService(cfg, &mockRNGService{})
+		if err != nil {
+			t.Fatalf("NewPaddingService() error = %v", err)
+		}
+
+		got, err := svc.generateRandomDigits(2)
+		if err != nil {
+			t.Fatalf("generateRandomDigits() error = %v", err)
+		}
+
+		if want := []string{"3", "3"}; !slices.Equal(got, want) {
+			t.Errorf("generateRandomDigits() = %v, want %v", got, want)
+		}
+	})
+
+	tests := []struct {
+		name string
+		cfg  *config.Settings
+		new  func(cfg *config.Settings) error
+	}{
+		{
+			name: "Ambiguous separator character",
+			cfg:  &config.Settings{AvoidAmbiguous: true, SeparatorCharacter: "|"},
+			new: func(cfg *config.Settings) error {
+				_, err := NewSeparatorService(cfg, &mockRNGService{})
+				return err
+			},
+		},
+		{
+			name: "Separator alphabet of ambiguous characters",
+			cfg: &config.Settings{
+				AvoidAmbiguous: true, SeparatorCharacter: option.SeparatorCharacterRandom,
+				SeparatorAlphabet: []string{"|", "l"},
+			},
+			new: func(cfg *config.Settings) error {
+				_, err := NewSeparatorService(cfg, &mockRNGService{})
+				return err
+			},
+		},
+		{
+			name: "Ambiguous padding character",
+			cfg:  &config.Settings{AvoidAmbiguous: true, PaddingType: option.PaddingTypeFixed, PaddingCharacter: "0"},
+			new: func(cfg *config.Settings) error {
+				_, err := NewPaddingService(cfg, &mockRNGService{})
+				return err
+			},
+		},
+		{
+			name: "Symbol alphabet of ambiguous characters",
+			cfg: &config.Settings{
+				AvoidAmbiguous: true, PaddingType: option.PaddingTypeFixed,
+				PaddingCharacter: option.PaddingCharacterRandom, SymbolAlphabet: []string{"|"},
+			},
+			new: func(cfg *config.Settings) error {
+				_, err := NewPaddingService(cfg, &mockRNGService{})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := tt.new(tt.cfg); err == nil {
+				t.Errorf("%s: error = nil, want an error", tt.name)
+			}
+		})
+	}
+}
+
+func TestNewWordListServiceAvoidAmbiguous(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Settings{
+		AvoidAmbiguous: true, CaseTransform: option.CaseTransformRandom, NumWords: 2,
+		WordList: option.WordListEN, WordLengthMin: 4, WordLengthMax: 6,
+	}
+
+	svc, err := NewWordListService(cfg, &mockRNGService{})
+	if err != nil {
+		t.Fatalf("NewWordListService() error = %v", err)
+	}
+
+	if len(svc.wordList) == 0 {
+		t.Fatal("NewWordListService() left no words")
+	}
+
+	for _, w := range svc.wordList {
+		if isAmbiguousWord(w, cfg.CaseTransform) {
+			t.Errorf("NewWordListService() kept the ambiguous word %q", w)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/eljamo/libpass/v8/asset"
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+// The number of times words are drawn again when adjacent words form a
+// blocked word, before giving up
+const blocklistMaxAttempts int = 100
+
+var ErrBlockedCompound = errors.New("failed to draw words which don't form a blocked word")
+
+// A set of words, and patterns matching words, which must not appear in
+// generated passwords.
+type blocklist struct {
+	words    map[string]bool // Blocked words in lower case
+	patterns []*regexp.Regexp
+}
+
+// Creates the blocklist set in the configuration from blocklist,
+// blocklist_file, blocklist_patterns and blocklist_default. It returns nil if
+// none are set, and an error if the blocklist file cannot be read or a
+// pattern is invalid.
+func newBlocklist(cfg *config.Settings) (*blocklist, error) {
+	words, err := blocklistWords(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(words) == 0 && len(cfg.BlocklistPatterns) == 0 {
+		return nil, nil
+	}
+
+	b := &blocklist{words: make(map[string]bool, len(words))}
+	for _, w := range words {
+		w = strings.ToLower(strings.TrimSpace(w))
+		if w != "" {
+			b.words[w] = true
+		}
+	}
+
+	for _, p := range cfg.BlocklistPatterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value (%s): %w", option.ConfigKeyBlocklistPatterns, p, err)
+		}
+		b.patterns = append(b.patterns, re)
+	}
+
+	return b, nil
+}
+
+// Returns the blocked words set in blocklist, read from blocklist_file, and
+// from the default blocklist when blocklist_default is set.
+func blocklistWords(cfg *config.Settings) ([]string, error) {
+	words := slices.Clone(cfg.Blocklist)
+
+	if cfg.BlocklistFile != "" {
+		fw, err := asset.GetFilteredWordListFromFile(cfg.BlocklistFile, 1, math.MaxInt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s (%s): %w", option.ConfigKeyBlocklistFile, cfg.BlocklistFile, err)
+		}
+		words = append(words, fw...)
+	}
+
+	if cfg.BlocklistDefault {
+		dw, err := asset.GetDefaultBlocklist()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the default blocklist: %w", err)
+		}
+		words = append(words, dw...)
+	}
+
+	return words, nil
+}
+
+// Reports whether a word is blocked, either because it is a blocked word,
+// compared case-insensitively, or matches a pattern.
+func (b *blocklist) blocks(word string) bool {
+	if b.words[strings.ToLower(word)] {
+		return true
+	}
+
+	for _, re := range b.patterns {
+		if re.MatchString(word) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reports whether any two adjacent words form a blocked word once the
+// separator between them is removed, i.e. a blocked word of any length starts
+// in the first word and ends in the second, or whether a pattern matches the
+// two words joined together.
+func (b *blocklist) blocksCompound(words []string) bool {
+	for i := 1; i < len(words); i++ {
+		first := strings.ToLower(words[i-1])
+		lower := first + strings.ToLower(words[i])
+		for w := range b.words {
+			if spansBoundary(lower, len(first), w) {
+				return true
+			}
+		}
+
+		pair := words[i-1] + words[i]
+
+		for _, re := range b.patterns {
+			if re.MatchString(pair) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Reports whether the word occurs in s starting before and ending after the
+// byte offset boundary.
+func spansBoundary(s string, boundary int, word string) bool {
+	for at := 0; at < boundary; {
+		idx := strings.Index(s[at:], word)
+		if idx < 0 || at+idx >= boundary {
+			return false
+		}
+
+		if at+idx+len(word) > boundary {
+			return true
+		}
+		at += idx + 1
+	}
+
+	return false
+}
+
+// Draws words with the given function until no adjacent words form a blocked
+// word. It returns ErrBlockedCompound if every attempt does. The few draws
+// rejected lower the entropy by a negligible amount, which the entropy
+// calculation ignores.
+func (b *blocklist) drawWords(draw func() ([]string, error)) ([]string, error) {
+	for range blocklistMaxAttempts {
+		wl, err := draw()
+		if err != nil {
+			return nil, err
+		}
+
+		if b == nil || !b.blocksCompound(wl) {
+			return wl, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w after %d attempts", ErrBlockedCompound, blocklistMaxAttempts)
+}
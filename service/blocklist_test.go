@@ -0,0 +1,183 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/eljamo/libpass/v8/config"
+)
+
+func TestNewBlocklist(t *testing.T) {
+	t.Parallel()
+
+	filePath := filepath.Join(t.TempDir(), "blocklist.txt")
+	if err := os.WriteFile(filePath, []byte("Durian\r\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		cfg       *config.Settings
+		wantNil   bool
+		wantErr   bool
+		blocked   []string
+		unblocked []string
+	}{
+		{
+			name:    "Nothing configured",
+			cfg:     &config.Settings{},
+			wantNil: true,
+		},
+		{
+			name:      "Words, file and patterns",
+			cfg:       &config.Settings{Blocklist: []string{" Apple "}, BlocklistFile: filePath, BlocklistPatterns: []string{"^ch"}},
+			blocked:   []string{"apple", "APPLE", "durian", "cherry"},
+			unblocked: []string{"banana", "pineapple", "Cherry"},
+		},
+		{
+			name:      "Default blocklist",
+			cfg:       &config.Settings{BlocklistDefault: true},
+			blocked:   []string{"fuck", "Shit"},
+			unblocked: []string{"banana"},
+		},
+		{
+			name:    "Missing file",
+			cfg:     &config.Settings{BlocklistFile: filepath.Join(t.TempDir(), "missing.txt")},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid pattern",
+			cfg:     &config.Settings{BlocklistPatterns: []string{"("}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			bl, err := newBlocklist(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newBlocklist() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if (bl == nil) != tt.wantNil {
+				t.Fatalf("newBlocklist() = %v, wantNil %v", bl, tt.wantNil)
+			}
+
+			for _, w := range tt.blocked {
+				if !bl.blocks(w) {
+					t.Errorf("blocks(%q) = false, want true", w)
+				}
+			}
+
+			for _, w := range tt.unblocked {
+				if bl.blocks(w) {
+					t.Errorf("blocks(%q) = true, want false", w)
+				}
+			}
+		})
+	}
+}
+
+func TestBlocklistBlocksCompound(t *testing.T) {
+	t.Parallel()
+
+	bl, err := newBlocklist(&config.Settings{Blocklist: []string{"asshole", "shit", "ass"}, BlocklistPatterns: []string{"nk.*ey", "^seapig$"}})
+	if err != nil {
+		t.Fatalf("newBlocklist() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		words []string
+		want  bool
+	}{
+		{name: "Across two words", words: []string{"Glass", "HOLE"}, want: true},
+		{name: "Across later words", words: []string{"ocean", "shi", "take"}, want: true},
+		{name: "Inside the first word", words: []string{"shitake", "ocean"}, want: false},
+		{name: "Inside the second word", words: []string{"ocean", "shitake"}, want: false},
+		{name: "Short blocked words across two words", words: []string{"bas", "sin"}, want: true},
+		{name: "Pattern across two words", words: []string{"monk", "key"}, want: true},
+		{name: "Pattern matching the joined words", words: []string{"sea", "pig"}, want: true},
+		{name: "Pattern matching neither the words nor the pair", words: []string{"sea", "pigeon"}, want: false},
+		{name: "Words which aren't adjacent", words: []string{"shi", "ocean", "take"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := bl.blocksCompound(tt.words); got != tt.want {
+				t.Errorf("blocksCompound(%v) = %v, want %v", tt.words, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlocklistDrawWords(t *testing.T) {
+	t.Parallel()
+
+	bl, err := newBlocklist(&config.Settings{Blocklist: []string{"shit"}})
+	if err != nil {
+		t.Fatalf("newBlocklist() error = %v", err)
+	}
+
+	draws := [][]string{{"shi", "take"}, {"ocean", "take"}}
+	calls := 0
+	got, err := bl.drawWords(func() ([]string, error) {
+		calls++
+
+		return draws[min(calls, len(draws))-1], nil
+	})
+	if err != nil {
+		t.Fatalf("drawWords() error = %v", err)
+	}
+
+	if !slices.Equal(got, draws[1]) || calls != 2 {
+		t.Errorf("drawWords() = %v after %d draws, want %v after 2", got, calls, draws[1])
+	}
+
+	_, err = bl.drawWords(func() ([]string, error) {
+		return draws[0], nil
+	})
+	if !errors.Is(err, ErrBlockedCompound) {
+		t.Errorf("drawWords() error = %v, want ErrBlockedCompound", err)
+	}
+
+	_, err = bl.drawWords(func() ([]string, error) {
+		return nil, errMockRNGService
+	})
+	if !errors.Is(err, errMockRNGService) {
+		t.Errorf("drawWords() error = %v, want the draw error", err)
+	}
+}
+
+func TestNewWordListServiceBlocklist(t *testing.T) {
+	t.Parallel()
+
+	const words = "apple\nFuck\nbanana\ncherry\n"
+
+	cfg := &config.Settings{NumWords: 2, WordLengthMin: 1, WordLengthMax: 10, BlocklistDefault: true, BlocklistPatterns: []string{"^ch"}}
+	svc, err := NewWordListServiceFromReader(cfg, &mockRNGService{}, strings.NewReader(words))
+	if err != nil {
+		t.Fatalf("NewWordListServiceFromReader() error = %v", err)
+	}
+
+	if want := []string{"apple", "banana"}; !slices.Equal(svc.WordList(), want) {
+		t.Errorf("WordList() = %v, want %v", svc.WordList(), want)
+	}
+
+	cfg = &config.Settings{NumWords: 2, WordLengthMin: 1, WordLengthMax: 10, BlocklistPatterns: []string{"."}}
+	if _, err := NewWordListServiceFromReader(cfg, &mockRNGService{}, strings.NewReader(words)); err == nil {
+		t.Error("NewWordListServiceFromReader() error = nil, want an error when every word is blocked")
+	}
+}
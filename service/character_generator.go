@@ -0,0 +1,361 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+const (
+	characterLengthMin int = 1
+	characterLengthMax int = 256
+	// The number of times characters are drawn again when they don't meet
+	// the character class minimums, before giving up
+	characterMaxAttempts int = 10000
+	// Character class minimums must be met by at least one in this many
+	// draws, so running out of attempts is practically impossible
+	characterMinAcceptance int = 250
+)
+
+var ErrCharacterClassMinimums = errors.New("failed to draw characters which meet the character class minimums")
+
+// A class of characters passwords are drawn from and the minimum number of
+// characters each password has from it.
+type characterClass struct {
+	name  string
+	chars []string
+	min   int
+}
+
+// CharacterPasswordGeneratorService implements the PasswordGeneratorService
+// interface, generating passwords of random characters instead of words, for
+// uses such as service accounts and database users which need opaque strings.
+// Every character is drawn from the union of the character_classes, and the
+// characters are drawn again until each class has its minimum number of
+// characters, so every password meeting the minimums is equally likely. It
+// also implements the EntropyService interface.
+type CharacterPasswordGeneratorService struct {
+	cfg      *config.Settings
+	rngSvc   RNGService
+	classes  []characterClass
+	alphabet []string // The characters of every class
+	bits     float64  // The seen entropy of a password
+}
+
+// Creates a new instance of CharacterPasswordGeneratorService from the
+// length, character_classes, custom_characters and symbol_alphabet settings.
+// It returns an error if the configuration is invalid, and an
+// EntropyBelowMinimumError if min_entropy_bits is set and the seen entropy is
+// below it.
+func NewCharacterPasswordGeneratorService(
+	cfg *config.Settings,
+	rngSvc RNGService,
+) (*CharacterPasswordGeneratorService, error) {
+	if err := validateNumPasswords(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validatePolicy(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validateNoSubstitutions(cfg, option.ConfigKeyGenerator, option.GeneratorCharacters); err != nil {
+		return nil, err
+	}
+
+	if cfg.Length < characterLengthMin || cfg.Length > characterLengthMax {
+		return nil, fmt.Errorf(
+			"%s (%d) must be between %d and %d",
+			option.ConfigKeyLength,
+			cfg.Length,
+			characterLengthMin,
+			characterLengthMax,
+		)
+	}
+
+	classes, err := newCharacterClasses(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	svc := &CharacterPasswordGeneratorService{cfg: cfg, rngSvc: rngSvc, classes: classes}
+	for _, c := range classes {
+		svc.alphabet = append(svc.alphabet, c.chars...)
+	}
+
+	if err := validatePolicyCharacters(cfg, svc.alphabet, option.ConfigKeyCharacterClasses); err != nil {
+		return nil, err
+	}
+
+	if err := svc.setEntropy(); err != nil {
+		return nil, err
+	}
+
+	if cfg.MinEntropyBits > 0 && svc.bits < float64(cfg.MinEntropyBits) {
+		return nil, &EntropyBelowMinimumError{
+			Seen: svc.bits,
+			Min:  cfg.MinEntropyBits,
+			Keys: []string{option.ConfigKeyLength, option.ConfigKeyCharacterClasses},
+		}
+	}
+
+	return svc, nil
+}
+
+// Creates the character classes set in character_classes, in the order of
+// option.CharacterClasses, leaving out ambiguous characters when
+// avoid_ambiguous is set. It returns an error if a class is unknown, empty
+// or shares characters with another class, or a minimum is negative or the
+// minimums add up to more than the length.
+func newCharacterClasses(cfg *config.Settings) ([]characterClass, error) {
+	if len(cfg.CharacterClasses) == 0 {
+		return nil, fmt.Errorf("%s cannot be empty", option.ConfigKeyCharacterClasses)
+	}
+
+	for name := range cfg.CharacterClasses {
+		if !slices.Contains(option.CharacterClasses, name) {
+			return nil, fmt.Errorf("invalid %s value (%s)", option.ConfigKeyCharacterClasses, name)
+		}
+	}
+
+	classes := make([]characterClass, 0, len(cfg.CharacterClasses))
+	seen := make(map[string]string)
+	total := 0
+	for _, name := range option.CharacterClasses {
+		minCount, ok := cfg.CharacterClasses[name]
+		if !ok {
+			continue
+		}
+
+		if minCount < 0 {
+			return nil, fmt.Errorf("%s minimum for %s (%d) must be greater than or equal to 0", option.ConfigKeyCharacterClasses, name, minCount)
+		}
+		total += minCount
+
+		chars, err := characterClassChars(cfg, name)
+		if err != nil {
+			return nil, err
+		}
+
+		chars = effectiveAlphabet(cfg, chars)
+		if len(chars) == 0 {
+			return nil, fmt.Errorf("%s class %s has no characters", option.ConfigKeyCharacterClasses, name)
+		}
+
+		for _, c := range chars {
+			if other, ok := seen[c]; ok {
+				return nil, fmt.Errorf("%s classes %s and %s both contain %q", option.ConfigKeyCharacterClasses, other, name, c)
+			}
+			seen[c] = name
+		}
+
+		classes = append(classes, characterClass{name: name, chars: chars, min: minCount})
+	}
+
+	if total > cfg.Length {
+		return nil, fmt.Errorf(
+			"%s minimums (%d) cannot add up to more than %s (%d)",
+			option.ConfigKeyCharacterClasses,
+			total,
+			option.ConfigKeyLength,
+			cfg.Length,
+		)
+	}
+
+	return classes, nil
+}
+
+// Returns the characters of the named character class. SYMBOLS are the
+// characters of symbol_alphabet and CUSTOM those of custom_characters.
+func characterClassChars(cfg *config.Settings, name string) ([]string, error) {
+	switch name {
+	case option.CharacterClassLower:
+		return charRange('a', 'z'), nil
+	case option.CharacterClassUpper:
+		return charRange('A', 'Z'), nil
+	case option.CharacterClassDigits:
+		return charRange('0', '9'), nil
+	case option.CharacterClassSymbols:
+		for _, c := range cfg.SymbolAlphabet {
+			if utf8.RuneCountInString(c) != 1 {
+				return nil, fmt.Errorf("%s elements must be a single character, found %q", option.ConfigKeySymbolAlphabet, c)
+			}
+		}
+
+		return slices.Compact(slices.Sorted(slices.Values(cfg.SymbolAlphabet))), nil
+	}
+
+	chars := strings.Split(cfg.CustomCharacters, "")
+	slices.Sort(chars)
+
+	return slices.Compact(chars), nil
+}
+
+// Returns the characters from first to last inclusive.
+func charRange(first, last rune) []string {
+	chars := make([]string, 0, last-first+1)
+	for r := first; r <= last; r++ {
+		chars = append(chars, string(r))
+	}
+
+	return chars
+}
+
+// Calculates the seen entropy, the number of passwords meeting the character
+// class minimums, and checks enough draws meet the minimums. It returns an
+// error if the minimums are too strict for the length.
+func (s *CharacterPasswordGeneratorService) setEntropy() error {
+	count := s.countPasswords()
+	s.bits = log2Int(count)
+
+	// The share of draws of length characters from the alphabet which meet
+	// the minimums
+	draws := new(big.Int).Exp(big.NewInt(int64(len(s.alphabet))), big.NewInt(int64(s.cfg.Length)), nil)
+	if new(big.Int).Mul(count, big.NewInt(int64(characterMinAcceptance))).Cmp(draws) < 0 {
+		return fmt.Errorf(
+			"%s minimums are too strict for a %s of %d, fewer than 1 in %d passwords meet them",
+			option.ConfigKeyCharacterClasses,
+			option.ConfigKeyLength,
+			s.cfg.Length,
+			characterMinAcceptance,
+		)
+	}
+
+	return nil
+}
+
+// Returns the number of passwords of length characters which meet the
+// character class minimums. ways[n] counts the strings of n characters from
+// the classes so far which meet their minimums, and each class adds c of its
+// characters at any of the positions.
+func (s *CharacterPasswordGeneratorService) countPasswords() *big.Int {
+	length := s.cfg.Length
+	ways := make([]*big.Int, length+1)
+	for n := range ways {
+		ways[n] = new(big.Int)
+	}
+	ways[0].SetInt64(1)
+
+	for _, class := range s.classes {
+		size := big.NewInt(int64(len(class.chars)))
+		next := make([]*big.Int, length+1)
+		for n := range next {
+			next[n] = new(big.Int)
+			for c := class.min; c <= n; c++ {
+				if ways[n-c].Sign() == 0 {
+					continue
+				}
+
+				term := new(big.Int).Binomial(int64(n), int64(c))
+				term.Mul(term, new(big.Int).Exp(size, big.NewInt(int64(c)), nil))
+				term.Mul(term, ways[n-c])
+				next[n].Add(next[n], term)
+			}
+		}
+		ways = next
+	}
+
+	return ways[length]
+}
+
+// Returns the base 2 logarithm of a positive integer.
+func log2Int(x *big.Int) float64 {
+	mant := new(big.Float)
+	exp := new(big.Float).SetInt(x).MantExp(mant)
+	m, _ := mant.Float64()
+
+	return float64(exp) + math.Log2(m)
+}
+
+// Generate creates a list of passwords of random characters, following the
+// policy if one is set, and returns the list or the first error encountered.
+func (s *CharacterPasswordGeneratorService) Generate() ([]string, error) {
+	return generatePasswords(s.cfg, s.generateOne)
+}
+
+// Draws length characters from the alphabet until they meet the character
+// class minimums. It returns ErrCharacterClassMinimums if every attempt
+// fails.
+func (s *CharacterPasswordGeneratorService) generateOne() (string, error) {
+	for range characterMaxAttempts {
+		idxs, err := s.rngSvc.GenerateSliceWithMax(s.cfg.Length, len(s.alphabet))
+		if err != nil {
+			return "", err
+		}
+
+		if s.meetsMinimums(idxs) {
+			var sb strings.Builder
+			for _, idx := range idxs {
+				sb.WriteString(s.alphabet[idx])
+			}
+
+			return sb.String(), nil
+		}
+	}
+
+	return "", fmt.Errorf("%w after %d attempts", ErrCharacterClassMinimums, characterMaxAttempts)
+}
+
+// Reports whether the characters at the given alphabet indexes meet the
+// minimum of every character class.
+func (s *CharacterPasswordGeneratorService) meetsMinimums(idxs []int) bool {
+	start := 0
+	for _, class := range s.classes {
+		end := start + len(class.chars)
+		n := 0
+		for _, idx := range idxs {
+			if idx >= start && idx < end {
+				n++
+			}
+		}
+
+		if n < class.min {
+			return false
+		}
+		start = end
+	}
+
+	return true
+}
+
+// Calculate returns the blind and seen entropy of the passwords produced by
+// the service's configuration.
+func (s *CharacterPasswordGeneratorService) Calculate() (*Entropy, error) {
+	blind := float64(s.cfg.Length) * math.Log2(float64(s.characterPoolSize()))
+
+	return &Entropy{
+		BlindMin: blind,
+		BlindMax: blind,
+		Seen:     s.bits,
+	}, nil
+}
+
+// Returns the size of the pool of characters an attacker would have to search
+// to brute force a password, based on the character classes its characters
+// belong to.
+func (s *CharacterPasswordGeneratorService) characterPoolSize() int {
+	var lower, upper, digit, symbol bool
+	for _, c := range s.alphabet {
+		r, _ := utf8.DecodeRuneInString(c)
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	return poolSize(lower, upper, digit, symbol)
+}
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+func newCharacterTestSettings(length int, classes map[string]int) *config.Settings {
+	return &config.Settings{
+		NumPasswords:     2,
+		Length:           length,
+		CharacterClasses: classes,
+		SymbolAlphabet:   slices.Clone(option.DefaultSpecialCharacters),
+	}
+}
+
+func TestCharacterPasswordGeneratorServiceGenerate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		cfg    *config.Settings
+		want   string
+		wantAs error
+	}{
+		{
+			name: "Lower case letters",
+			cfg:  newCharacterTestSettings(4, map[string]int{option.CharacterClassLower: 0}),
+			want: "cccc",
+		},
+		{
+			name: "Digits meet their minimum",
+			cfg:  newCharacterTestSettings(4, map[string]int{option.CharacterClassDigits: 1, option.CharacterClassLower: 0}),
+			want: "2222",
+		},
+		{
+			// The mock RNG only draws digits, so the lower case minimum is never met
+			name:   "Minimums never met",
+			cfg:    newCharacterTestSettings(4, map[string]int{option.CharacterClassDigits: 0, option.CharacterClassLower: 1}),
+			wantAs: ErrCharacterClassMinimums,
+		},
+		{
+			name: "Avoid ambiguous leaves out 0 and 1",
+			cfg: func() *config.Settings {
+				cfg := newCharacterTestSettings(3, map[string]int{option.CharacterClassDigits: 0})
+				cfg.AvoidAmbiguous = true
+
+				return cfg
+			}(),
+			want: "444",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc, err := NewCharacterPasswordGeneratorService(tt.cfg, &mockRNGService{})
+			if err != nil {
+				t.Fatalf("NewCharacterPasswordGeneratorService() error = %v", err)
+			}
+
+			got, err := svc.Generate()
+			if tt.wantAs != nil {
+				if !errors.Is(err, tt.wantAs) {
+					t.Errorf("Generate() error = %v, want %v", err, tt.wantAs)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			if want := []string{tt.want, tt.want}; !slices.Equal(got, want) {
+				t.Errorf("Generate() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestCharacterPasswordGeneratorServiceMinimums(t *testing.T) {
+	t.Parallel()
+
+	cfg := newCharacterTestSettings(8, map[string]int{
+		option.CharacterClassLower:   2,
+		option.CharacterClassUpper:   2,
+		option.CharacterClassDigits:  2,
+		option.CharacterClassSymbols: 1,
+	})
+	cfg.NumPasswords = numPasswordMax
+
+	svc, err := NewCharacterPasswordGeneratorService(cfg, NewSeededRNGService([]byte("characters")))
+	if err != nil {
+		t.Fatalf("NewCharacterPasswordGeneratorService() error = %v", err)
+	}
+
+	for range presetContractIterations {
+		pws, err := svc.Generate()
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+
+		for _, pw := range pws {
+			if n := utf8.RuneCountInString(pw); n != cfg.Length {
+				t.Fatalf("Generate() password %q is %d runes, want %d", pw, n, cfg.Length)
+			}
+
+			var lower, upper, digits, symbols int
+			for _, r := range pw {
+				switch {
+				case unicode.IsLower(r):
+					lower++
+				case unicode.IsUpper(r):
+					upper++
+				case unicode.IsDigit(r):
+					digits++
+				case strings.ContainsRune(strings.Join(cfg.SymbolAlphabet, ""), r):
+					symbols++
+				default:
+					t.Fatalf("Generate() password %q contains %q, which is in no class", pw, r)
+				}
+			}
+
+			if lower < 2 || upper < 2 || digits < 2 || symbols < 1 {
+				t.Fatalf("Generate() password %q doesn't meet the class minimums", pw)
+			}
+		}
+	}
+}
+
+func TestNewCharacterPasswordGeneratorServiceErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		modify func(cfg *config.Settings)
+	}{
+		{
+			name:   "No character classes",
+			modify: func(cfg *config.Settings) { cfg.CharacterClasses = nil },
+		},
+		{
+			name:   "Unknown character class",
+			modify: func(cfg *config.Settings) { cfg.CharacterClasses["EMOJI"] = 1 },
+		},
+		{
+			name:   "Negative minimum",
+			modify: func(cfg *config.Settings) { cfg.CharacterClasses[option.CharacterClassLower] = -1 },
+		},
+		{
+			name:   "Minimums longer than the length",
+			modify: func(cfg *config.Settings) { cfg.CharacterClasses[option.CharacterClassDigits] = 9 },
+		},
+		{
+			name:   "Zero length",
+			modify: func(cfg *config.Settings) { cfg.Length = 0 },
+		},
+		{
+			name:   "Too long",
+			modify: func(cfg *config.Settings) { cfg.Length = characterLengthMax + 1 },
+		},
+		{
+			name:   "Invalid number of passwords",
+			modify: func(cfg *config.Settings) { cfg.NumPasswords = 0 },
+		},
+		{
+			name:   "Empty custom characters",
+			modify: func(cfg *config.Settings) { cfg.CharacterClasses[option.CharacterClassCustom] = 0 },
+		},
+		{
+			name: "Custom characters overlap another class",
+			modify: func(cfg *config.Settings) {
+				cfg.CharacterClasses[option.CharacterClassCustom] = 0
+				cfg.CustomCharacters = "a#"
+			},
+		},
+		{
+			name: "Multi-character symbol",
+			modify: func(cfg *config.Settings) {
+				cfg.CharacterClasses[option.CharacterClassSymbols] = 0
+				cfg.SymbolAlphabet = []string{"!!"}
+			},
+		},
+		{
+			name: "Minimums too strict",
+			modify: func(cfg *config.Settings) {
+				cfg.CharacterClasses[option.CharacterClassCustom] = 8
+				cfg.CustomCharacters = "#"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := newCharacterTestSettings(8, map[string]int{option.CharacterClassLower: 0, option.CharacterClassDigits: 0})
+			tt.modify(cfg)
+
+			if _, err := NewCharacterPasswordGeneratorService(cfg, &mockRNGService{}); err == nil {
+				t.Errorf("%s: NewCharacterPasswordGeneratorService() error = nil, want an error", tt.name)
+			}
+		})
+	}
+}
+
+func TestCharacterPasswordGeneratorServiceCalculate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		cfg  *config.Settings
+		want Entropy
+	}{
+		{
+			name: "Lower case letters",
+			cfg:  newCharacterTestSettings(4, map[string]int{option.CharacterClassLower: 0}),
+			want: Entropy{BlindMin: 4 * math.Log2(26), BlindMax: 4 * math.Log2(26), Seen: 4 * math.Log2(26)},
+		},
+		{
+			// Every pair of characters except those with no digit
+			name: "Digit minimum",
+			cfg:  newCharacterTestSettings(2, map[string]int{option.CharacterClassDigits: 1, option.CharacterClassLower: 0}),
+			want: Entropy{BlindMin: 2 * math.Log2(36), BlindMax: 2 * math.Log2(36), Seen: math.Log2(36*36 - 26*26)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc, err := NewCharacterPasswordGeneratorService(tt.cfg, &mockRNGService{})
+			if err != nil {
+				t.Fatalf("NewCharacterPasswordGeneratorService() error = %v", err)
+			}
+
+			got, err := svc.Calculate()
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+
+			if math.Abs(got.BlindMin-tt.want.BlindMin) > entropyTolerance ||
+				math.Abs(got.BlindMax-tt.want.BlindMax) > entropyTolerance ||
+				math.Abs(got.Seen-tt.want.Seen) > entropyTolerance {
+				t.Errorf("Calculate() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+
+	cfg := newCharacterTestSettings(4, map[string]int{option.CharacterClassLower: 0})
+	cfg.MinEntropyBits = 20
+
+	_, err := NewCharacterPasswordGeneratorService(cfg, &mockRNGService{})
+	var belowErr *EntropyBelowMinimumError
+	if !errors.As(err, &belowErr) {
+		t.Errorf("NewCharacterPasswordGeneratorService() error = %v, want an EntropyBelowMinimumError", err)
+	}
+}
+
+func TestNewGeneratorService(t *testing.T) {
+	t.Parallel()
+
+	words := config.DefaultSettings()
+
+	characters := newCharacterTestSettings(16, map[string]int{option.CharacterClassLower: 1})
+	characters.Generator = option.GeneratorCharacters
+
+	pin := &config.Settings{Generator: option.GeneratorPIN, NumPasswords: 1, Length: 6}
+
+	invalid := config.DefaultSettings()
+	invalid.Generator = "invalid"
+
+	tests := []struct {
+		name    string
+		cfg     *config.Settings
+		want    string
+		wantErr bool
+	}{
+		{name: "Words by default", cfg: words, want: "*service.DefaultPasswordGeneratorService"},
+		{name: "Characters", cfg: characters, want: "*service.CharacterPasswordGeneratorService"},
+		{name: "PIN", cfg: pin, want: "*service.PINPasswordGeneratorService"},
+		{name: "Invalid generator", cfg: invalid, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc, err := NewGeneratorService(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewGeneratorService() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got := reflect.TypeOf(svc).String(); got != tt.want {
+				t.Errorf("NewGeneratorService() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/eljamo/libpass/v8/asset"
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+// A word list drawn from by a CompositeWordListService, with the relative
+// weight it is chosen with.
+type weightedWordList struct {
+	name     string
+	weight   int
+	wordList []string
+}
+
+// Implements the interface WordListService, extracting words from several
+// word lists, either chosen for each word by weight (word_list_weights) or
+// assigned to each word in turn (word_list_slots).
+type CompositeWordListService struct {
+	cfg         *config.Settings
+	rngSvc      RNGService
+	lists       []weightedWordList // Sorted by name with weights, in slot order with slots
+	totalWeight int
+	union       []string   // The distinct words of every list, in the order first seen
+	blocklist   *blocklist // Set when a blocklist is configured
+	longer      bool       // Whether a word list has kept words longer than word_length_max
+}
+
+// Creates a new instance of CompositeWordListService from the word lists set
+// in word_list_weights or word_list_slots, which are used instead of
+// word_list and word_list_file. Each word list is filtered by length in the
+// same way as a single word list. It returns an error if the configuration is
+// invalid or a word list cannot be read.
+func NewCompositeWordListService(cfg *config.Settings, rngSvc RNGService) (*CompositeWordListService, error) {
+	if err := validateCompositeWordList(cfg); err != nil {
+		return nil, err
+	}
+
+	var lists []weightedWordList
+	if len(cfg.WordListSlots) > 0 {
+		for _, name := range cfg.WordListSlots {
+			lists = append(lists, weightedWordList{name: name, weight: 1})
+		}
+	} else {
+		for _, name := range slices.Sorted(maps.Keys(cfg.WordListWeights)) {
+			lists = append(lists, weightedWordList{name: name, weight: cfg.WordListWeights[name]})
+		}
+	}
+
+	bl, err := newBlocklist(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := newWordFilter(cfg, bl)
+	if err != nil {
+		return nil, err
+	}
+
+	svc := &CompositeWordListService{cfg: cfg, rngSvc: rngSvc, lists: lists, blocklist: bl}
+
+	seen := make(map[string]bool)
+	for i := range svc.lists {
+		l := &svc.lists[i]
+		source := fmt.Sprintf("%s (%s)", option.ConfigKeyWordList, l.name)
+
+		load := func(minLen, maxLen int) ([]string, error) {
+			return asset.GetWordListView(l.name, minLen, maxLen)
+		}
+
+		wl, err := getWordList(source, cfg.WordLengthMin, cfg.WordLengthMax, load, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		if !svc.longer {
+			if svc.longer, err = hasLongerWords(cfg, load, filter); err != nil {
+				return nil, err
+			}
+		}
+
+		l.wordList = wl
+		svc.totalWeight += l.weight
+
+		for _, w := range wl {
+			if !seen[w] {
+				seen[w] = true
+				svc.union = append(svc.union, w)
+			}
+		}
+	}
+
+	return svc, nil
+}
+
+// Checks the configuration of a CompositeWordListService for correctness. It
+// ensures exactly one of word_list_weights and word_list_slots is set, every
+// weight is positive, there is a slot for every word, and unique_words isn't
+// set. Returns an error if the configuration is invalid.
+func validateCompositeWordList(cfg *config.Settings) error {
+	if cfg.NumWords < numWordMin {
+		return fmt.Errorf("%s must be greater than or equal to %d", option.ConfigKeyNumWords, numWordMin)
+	}
+
+	if len(cfg.WordListWeights) > 0 && len(cfg.WordListSlots) > 0 {
+		return fmt.Errorf("%s and %s cannot both be set", option.ConfigKeyWordListWeights, option.ConfigKeyWordListSlots)
+	}
+
+	if len(cfg.WordListWeights) == 0 && len(cfg.WordListSlots) == 0 {
+		return fmt.Errorf("one of %s or %s must be set", option.ConfigKeyWordListWeights, option.ConfigKeyWordListSlots)
+	}
+
+	if cfg.UniqueWords {
+		return fmt.Errorf(
+			"%s cannot be set with %s or %s",
+			option.ConfigKeyUniqueWords,
+			option.ConfigKeyWordListWeights,
+			option.ConfigKeyWordListSlots,
+		)
+	}
+
+	for name, weight := range cfg.WordListWeights {
+		if weight < 1 {
+			return fmt.Errorf("%s weight for %s (%d) must be greater than or equal to 1", option.ConfigKeyWordListWeights, name, weight)
+		}
+	}
+
+	if len(cfg.WordListSlots) > 0 && len(cfg.WordListSlots) != cfg.NumWords {
+		return fmt.Errorf(
+			"%s must have one word list for each word, %s is %d but %d were given",
+			option.ConfigKeyWordListSlots,
+			option.ConfigKeyNumWords,
+			cfg.NumWords,
+			len(cfg.WordListSlots),
+		)
+	}
+
+	return nil
+}
+
+// Creates a slice of words randomly extracted from the word lists. With
+// word_list_slots each word is drawn from its slot's word list, otherwise a
+// word list is chosen by weight for each word and the word is drawn from it.
+// When a blocklist is configured the words are drawn again while adjacent
+// words form a blocked word. It returns an error if the slice cannot be
+// created.
+func (s *CompositeWordListService) GetWords() ([]string, error) {
+	return s.blocklist.drawWords(s.getWords)
+}
+
+// Creates a slice of words randomly extracted from the word lists.
+func (s *CompositeWordListService) getWords() ([]string, error) {
+	wl := make([]string, s.cfg.NumWords)
+	for i := range wl {
+		l, err := s.listFor(i)
+		if err != nil {
+			return nil, err
+		}
+
+		idx, err := s.rngSvc.GenerateWithMax(len(l.wordList))
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate random word index number: %w", err)
+		}
+
+		if idx < 0 || idx >= len(l.wordList) {
+			return nil, fmt.Errorf("number (%d) given out of range of word list %s (%d)", idx, l.name, len(l.wordList))
+		}
+
+		wl[i] = l.wordList[idx]
+	}
+
+	return wl, nil
+}
+
+// Returns the word list the word at the given index in a password is drawn
+// from: its slot's word list with word_list_slots, and a word list chosen by
+// weight otherwise.
+func (s *CompositeWordListService) listFor(i int) (weightedWordList, error) {
+	if len(s.cfg.WordListSlots) > 0 {
+		return s.lists[i], nil
+	}
+
+	return s.chooseList()
+}
+
+// Chooses a word list at random in proportion to its weight.
+func (s *CompositeWordListService) chooseList() (weightedWordList, error) {
+	n, err := s.rngSvc.GenerateWithMax(s.totalWeight)
+	if err != nil {
+		return weightedWordList{}, fmt.Errorf("failed to generate random word list number: %w", err)
+	}
+
+	for _, l := range s.lists {
+		if n < l.weight {
+			return l, nil
+		}
+		n -= l.weight
+	}
+
+	return weightedWordList{}, fmt.Errorf("number given out of range of %s total weight (%d)", option.ConfigKeyWordListWeights, s.totalWeight)
+}
+
+// WordList returns a copy of the distinct words of every word list, which
+// words are extracted from.
+func (s *CompositeWordListService) WordList() []string {
+	return slices.Clone(s.union)
+}
+
+func (s *CompositeWordListService) words() []string {
+	return s.union
+}
+
+func (s *CompositeWordListService) hasLongerWords() bool {
+	return s.longer
+}
+
+// WordEntropy returns the seen entropy in bits of the words in a password.
+// With word_list_slots it is the sum of the entropy of each slot's word list.
+// With word_list_weights a word found in several word lists can be drawn from
+// any of them, so each word contributes the min-entropy of the union: that of
+// its most likely word.
+func (s *CompositeWordListService) WordEntropy() float64 {
+	if len(s.cfg.WordListSlots) > 0 {
+		bits := 0.0
+		for _, l := range s.lists {
+			bits += math.Log2(float64(len(l.wordList)))
+		}
+
+		return bits
+	}
+
+	probs := make(map[string]float64, len(s.union))
+	for _, l := range s.lists {
+		p := float64(l.weight) / float64(s.totalWeight) / float64(len(l.wordList))
+		for _, w := range l.wordList {
+			probs[w] += p
+		}
+	}
+
+	maxProb := 0.0
+	for _, p := range probs {
+		maxProb = max(maxProb, p)
+	}
+
+	return -float64(s.cfg.NumWords) * math.Log2(maxProb)
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/eljamo/libpass/v8/asset"
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+const (
+	compositeTestWordListA = "TEST_COMPOSITE_A"
+	compositeTestWordListB = "TEST_COMPOSITE_B"
+)
+
+var registerCompositeTestWordLists = sync.OnceValue(func() error {
+	if err := asset.WordLists.Register(compositeTestWordListA, "Composite test words A", asset.BytesSource([]byte("alpha\nbravo\ncharlie\ndelta\n"))); err != nil {
+		return err
+	}
+
+	return asset.WordLists.Register(compositeTestWordListB, "Composite test words B", asset.BytesSource([]byte("delta\necho\nfoxtrot\n")))
+})
+
+func newCompositeTestSettings(t *testing.T) *config.Settings {
+	t.Helper()
+
+	if err := registerCompositeTestWordLists(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	return &config.Settings{NumWords: 2, WordLengthMin: 1, WordLengthMax: 10}
+}
+
+func TestCompositeWordListServiceGetWords(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		slots   []string
+		weights map[string]int
+		want    []string
+	}{
+		{
+			name:  "Slots",
+			slots: []string{compositeTestWordListA, compositeTestWordListB},
+			want:  []string{"bravo", "echo"},
+		},
+		{
+			// The mock RNG draws 1, choosing B by weight and its second word
+			name:    "Weights",
+			weights: map[string]int{compositeTestWordListA: 1, compositeTestWordListB: 3},
+			want:    []string{"echo", "echo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := newCompositeTestSettings(t)
+			cfg.WordListSlots = tt.slots
+			cfg.WordListWeights = tt.weights
+
+			svc, err := NewCompositeWordListService(cfg, &mockRNGService{})
+			if err != nil {
+				t.Fatalf("NewCompositeWordListService() error = %v", err)
+			}
+
+			got, err := svc.GetWords()
+			if err != nil {
+				t.Fatalf("GetWords() error = %v", err)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("GetWords() = %v, want %v", got, tt.want)
+			}
+
+			if want := []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot"}; !slices.Equal(svc.WordList(), want) {
+				t.Errorf("WordList() = %v, want %v", svc.WordList(), want)
+			}
+
+			errSvc, err := NewCompositeWordListService(cfg, &mockErrRNGService{})
+			if err != nil {
+				t.Fatalf("NewCompositeWordListService() error = %v", err)
+			}
+
+			if _, err := errSvc.GetWords(); err == nil {
+				t.Error("GetWords() error = nil, want the RNG error")
+			}
+		})
+	}
+}
+
+func TestNewCompositeWordListServiceErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		modify func(cfg *config.Settings)
+	}{
+		{
+			name: "Neither weights nor slots",
+			modify: func(*config.Settings) {
+			},
+		},
+		{
+			name: "Both weights and slots",
+			modify: func(cfg *config.Settings) {
+				cfg.WordListWeights = map[string]int{compositeTestWordListA: 1}
+				cfg.WordListSlots = []string{compositeTestWordListA, compositeTestWordListB}
+			},
+		},
+		{
+			name: "Zero weight",
+			modify: func(cfg *config.Settings) {
+				cfg.WordListWeights = map[string]int{compositeTestWordListA: 1, compositeTestWordListB: 0}
+			},
+		},
+		{
+			name: "Fewer slots than words",
+			modify: func(cfg *config.Settings) {
+				cfg.WordListSlots = []string{compositeTestWordListA}
+			},
+		},
+		{
+			name: "Unknown word list",
+			modify: func(cfg *config.Settings) {
+				cfg.WordListSlots = []string{compositeTestWordListA, "MISSING"}
+			},
+		},
+		{
+			name: "No words of the given length",
+			modify: func(cfg *config.Settings) {
+				cfg.WordListSlots = []string{compositeTestWordListA, compositeTestWordListB}
+				cfg.WordLengthMin = 8
+			},
+		},
+		{
+			name: "Unique words",
+			modify: func(cfg *config.Settings) {
+				cfg.WordListSlots = []string{compositeTestWordListA, compositeTestWordListB}
+				cfg.UniqueWords = true
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := newCompositeTestSettings(t)
+			tt.modify(cfg)
+
+			if _, err := NewCompositeWordListService(cfg, &mockRNGService{}); err == nil {
+				t.Errorf("%s: NewCompositeWordListService() error = nil, want an error", tt.name)
+			}
+		})
+	}
+}
+
+func TestCompositeWordListServiceWordEntropy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		slots   []string
+		weights map[string]int
+		want    float64
+	}{
+		{
+			name:  "Slots",
+			slots: []string{compositeTestWordListA, compositeTestWordListB},
+			want:  math.Log2(4) + math.Log2(3),
+		},
+		{
+			// delta is in both lists, so it is drawn with probability 1/8 + 1/6
+			name:    "Weights",
+			weights: map[string]int{compositeTestWordListA: 1, compositeTestWordListB: 1},
+			want:    -2 * math.Log2(7.0/24),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := newCompositeTestSettings(t)
+			cfg.WordListSlots = tt.slots
+			cfg.WordListWeights = tt.weights
+			cfg.PaddingType = option.PaddingTypeNone
+
+			svc, err := NewCompositeWordListService(cfg, &mockRNGService{})
+			if err != nil {
+				t.Fatalf("NewCompositeWordListService() error = %v", err)
+			}
+
+			es, err := NewEntropyServiceFromWordList(cfg, svc)
+			if err != nil {
+				t.Fatalf("NewEntropyServiceFromWordList() error = %v", err)
+			}
+
+			got, err := es.Calculate()
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+
+			if math.Abs(got.Seen-tt.want) > entropyTolerance {
+				t.Errorf("Calculate() Seen = %v, want %v", got.Seen, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPasswordGeneratorServiceWithWordListSlots(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.DefaultSettings()
+	cfg.NumWords = 2
+	cfg.WordListSlots = []string{option.WordListPokemon, option.WordListMiddleEarth}
+
+	svc, err := NewPasswordGeneratorServiceWithRNG(cfg, NewSeededRNGService([]byte("slots")))
+	if err != nil {
+		t.Fatalf("NewPasswordGeneratorServiceWithRNG() error = %v", err)
+	}
+
+	if _, ok := svc.wordListSvc.(*CompositeWordListService); !ok {
+		t.Errorf("wordListSvc = %T, want *CompositeWordListService", svc.wordListSvc)
+	}
+
+	if _, err := svc.Generate(); err != nil {
+		t.Errorf("Generate() error = %v", err)
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+func TestNewDiceRNGService(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		rolls   string
+		want    int
+		wantErr bool
+	}{
+		{name: "Rolls", rolls: "123456", want: 6},
+		{name: "Whitespace between rolls", rolls: "12345 66666\n1\t2", want: 12},
+		{name: "Empty", rolls: "", want: 0},
+		{name: "Seven", rolls: "1237", wantErr: true},
+		{name: "Zero", rolls: "0", wantErr: true},
+		{name: "Separator", rolls: "12-34", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc, err := NewDiceRNGService(tt.rolls)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewDiceRNGService(%q) error = %v, wantErr %v", tt.rolls, err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidDiceRoll) {
+					t.Errorf("NewDiceRNGService(%q) error = %v, want ErrInvalidDiceRoll", tt.rolls, err)
+				}
+
+				return
+			}
+
+			if got := svc.Remaining(); got != tt.want {
+				t.Errorf("Remaining() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiceRNGGenerateWithMax(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		rolls         string
+		max           int
+		want          []int
+		wantRemaining int
+		wantErr       error
+	}{
+		{
+			name:  "Power of six reads rolls as base six digits",
+			rolls: "11111 66666 12345",
+			max:   7776,
+			want:  []int{0, 7775, 310},
+		},
+		{
+			name:          "Rolls outside the largest multiple are drawn again",
+			rolls:         "6 3 1",
+			max:           5,
+			want:          []int{2},
+			wantRemaining: 1,
+		},
+		{
+			name:          "Max of one needs no rolls",
+			rolls:         "1",
+			max:           1,
+			want:          []int{0, 0},
+			wantRemaining: 1,
+		},
+		{
+			name:    "Not enough rolls",
+			rolls:   "1234",
+			max:     7776,
+			want:    []int{0},
+			wantErr: ErrDiceRollsExhausted,
+		},
+		{
+			name:    "Max less than one",
+			rolls:   "1",
+			max:     0,
+			want:    []int{0},
+			wantErr: ErrRNGMaxLessThanOne,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc, err := NewDiceRNGService(tt.rolls)
+			if err != nil {
+				t.Fatalf("NewDiceRNGService() error = %v", err)
+			}
+
+			got := make([]int, len(tt.want))
+			for i := range got {
+				got[i], err = svc.GenerateWithMax(tt.max)
+				if err != nil {
+					break
+				}
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GenerateWithMax(%d) error = %v, want %v", tt.max, err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("GenerateWithMax(%d) = %v, want %v", tt.max, got, tt.want)
+			}
+
+			if svc.Remaining() != tt.wantRemaining {
+				t.Errorf("Remaining() = %d, want %d", svc.Remaining(), tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestDiceRNGGenerateWithMaxTooLarge(t *testing.T) {
+	t.Parallel()
+
+	svc, err := NewDiceRNGService("")
+	if err != nil {
+		t.Fatalf("NewDiceRNGService() error = %v", err)
+	}
+
+	if _, err := svc.GenerateWithMax(maxDiceValue + 1); err == nil {
+		t.Error("GenerateWithMax() error = nil, want an error for a max above maxDiceValue")
+	}
+}
+
+func TestDiceRNGEFFLargeWords(t *testing.T) {
+	t.Parallel()
+
+	svc, err := NewDiceRNGService("11111 66666 12345")
+	if err != nil {
+		t.Fatalf("NewDiceRNGService() error = %v", err)
+	}
+
+	cfg := &config.Settings{NumWords: 3, WordList: option.WordListEFFLarge, WordLengthMin: 1, WordLengthMax: 10}
+	wls, err := NewWordListService(cfg, svc)
+	if err != nil {
+		t.Fatalf("NewWordListService() error = %v", err)
+	}
+
+	got, err := wls.GetWords()
+	if err != nil {
+		t.Fatalf("GetWords() error = %v", err)
+	}
+
+	if want := []string{"abacus", "zoom", "arousal"}; !slices.Equal(got, want) {
+		t.Errorf("GetWords() = %v, want %v", got, want)
+	}
+
+	if _, err := wls.GetWords(); !errors.Is(err, ErrDiceRollsExhausted) {
+		t.Errorf("GetWords() error = %v, want ErrDiceRollsExhausted", err)
+	}
+}
@@ -0,0 +1,634 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+// Sizes of the character classes an attacker brute forcing a password is
+// assumed to search, matching the classes used by xkpasswd.net
+const (
+	poolSizeLower  int = 26
+	poolSizeUpper  int = 26
+	poolSizeDigit  int = 10
+	poolSizeSymbol int = 33
+)
+
+var ErrEntropyBelowMinimum = errors.New("entropy is below the minimum")
+
+// EntropyBelowMinimumError is returned when the seen entropy of a
+// configuration falls below its min_entropy_bits. It unwraps to
+// ErrEntropyBelowMinimum.
+type EntropyBelowMinimumError struct {
+	// The seen entropy of the configuration in bits
+	Seen float64
+	// The minimum seen entropy required in bits
+	Min int
+	// The configuration keys which can be changed to raise the entropy
+	Keys []string
+}
+
+func (e *EntropyBelowMinimumError) Error() string {
+	return fmt.Sprintf(
+		"seen entropy (%.2f bits) is below %s (%d), raise one of: %s",
+		e.Seen,
+		option.ConfigKeyMinEntropyBits,
+		e.Min,
+		strings.Join(e.Keys, ", "),
+	)
+}
+
+func (e *EntropyBelowMinimumError) Unwrap() error {
+	return ErrEntropyBelowMinimum
+}
+
+// Entropy holds the entropy, in bits, of the passwords produced by a
+// configuration.
+type Entropy struct {
+	// The brute force entropy of the shortest possible password, for an
+	// attacker who only knows which character classes are used
+	BlindMin float64
+	// The brute force entropy of the longest possible password, for an
+	// attacker who only knows which character classes are used
+	BlindMax float64
+	// The entropy for an attacker who knows the configuration and word list
+	Seen float64
+}
+
+// Defines the interface for a service that calculates the entropy of the
+// passwords produced by a configuration.
+type EntropyService interface {
+	// Calculate returns the entropy of the passwords produced by the
+	// configuration or an error if it cannot be calculated.
+	Calculate() (*Entropy, error)
+}
+
+// WordEntropyReporter is implemented by word list services which can report
+// the entropy of the words they extract, such as DefaultWordListService and
+// CompositeWordListService.
+type WordEntropyReporter interface {
+	// WordList returns the distinct words which words are extracted from.
+	WordList() []string
+	// WordEntropy returns the seen entropy in bits of the words in a password.
+	WordEntropy() float64
+}
+
+// Implements the EntropyService, calculating entropy for the pipeline used
+// by DefaultPasswordGeneratorService.
+type DefaultEntropyService struct {
+	cfg      *config.Settings
+	wordList []string
+	wordBits float64 // The seen entropy of the words in a password
+	wordMin  int     // The length in runes of the shortest word
+	wordMax  int     // The length in runes of the longest word
+	longer   bool    // Whether raising word_length_max adds words
+}
+
+// Creates a new instance of DefaultEntropyService for the given configuration
+// and the filtered word list words are drawn from, as built by
+// NewWordListService. It returns an error if the configuration is invalid.
+func NewEntropyService(cfg *config.Settings, wordList []string) (*DefaultEntropyService, error) {
+	svc := &DefaultEntropyService{cfg: cfg, wordList: wordList}
+	svc.wordMin, svc.wordMax = wordLengthRange(wordList)
+
+	if err := svc.validate(); err != nil {
+		return nil, err
+	}
+
+	svc.wordBits = wordListEntropy(cfg, wordList)
+
+	return svc, nil
+}
+
+// Creates a new instance of DefaultEntropyService for the given configuration
+// and the word list service words are extracted from, which reports the
+// entropy of the words, e.g. a CompositeWordListService drawing from several
+// word lists. It returns an error if the configuration is invalid.
+func NewEntropyServiceFromWordList(cfg *config.Settings, wls WordEntropyReporter) (*DefaultEntropyService, error) {
+	svc := &DefaultEntropyService{cfg: cfg, wordList: reportedWords(wls)}
+	svc.wordMin, svc.wordMax = reportedWordLengthRange(wls)
+
+	if r, ok := wls.(longerWordsReporter); ok {
+		svc.longer = r.hasLongerWords()
+	}
+
+	if err := svc.validate(); err != nil {
+		return nil, err
+	}
+
+	svc.wordBits = wls.WordEntropy()
+
+	return svc, nil
+}
+
+// A word list service of this package which shares its words without copying
+// them, unlike WordList.
+type wordSharer interface {
+	words() []string
+}
+
+// Returns the words of the word list service, without copying them when it is
+// a wordSharer.
+func reportedWords(wls WordEntropyReporter) []string {
+	if ws, ok := wls.(wordSharer); ok {
+		return ws.words()
+	}
+
+	return wls.WordList()
+}
+
+// A word list service of this package which generates words of any length
+// within a range, rather than drawing them from its word list.
+type lengthRanger interface {
+	lengthRange() (int, int)
+}
+
+// Returns the length in runes of the shortest and longest words the word
+// list service can extract: the word length range for a lengthRanger, such
+// as PronounceableWordListService, and the range of its word list otherwise.
+func reportedWordLengthRange(wls WordEntropyReporter) (int, int) {
+	if r, ok := wls.(lengthRanger); ok {
+		return r.lengthRange()
+	}
+
+	return wordLengthRange(reportedWords(wls))
+}
+
+// A word list service of this package which reports whether its word lists
+// have words longer than word_length_max, which raising it would add.
+type longerWordsReporter interface {
+	hasLongerWords() bool
+}
+
+// Calculate returns the blind and seen entropy of the passwords produced by
+// the service's configuration.
+func (s *DefaultEntropyService) Calculate() (*Entropy, error) {
+	minLen, maxLen := s.lengthRange()
+	poolBits := math.Log2(float64(s.characterPoolSize()))
+
+	return &Entropy{
+		BlindMin: float64(minLen) * poolBits,
+		BlindMax: float64(maxLen) * poolBits,
+		Seen:     s.seen(),
+	}, nil
+}
+
+// Checks the seen entropy of the configuration against min_entropy_bits. It
+// returns an EntropyBelowMinimumError naming the settings to raise if the
+// entropy is too low.
+func (s *DefaultEntropyService) checkMinimum() error {
+	if s.cfg.MinEntropyBits == 0 {
+		return nil
+	}
+
+	seen := s.seen()
+	if seen >= float64(s.cfg.MinEntropyBits) {
+		return nil
+	}
+
+	return &EntropyBelowMinimumError{
+		Seen: seen,
+		Min:  s.cfg.MinEntropyBits,
+		Keys: s.raisableKeys(),
+	}
+}
+
+// Returns the configuration keys which can be changed to raise the seen
+// entropy: the word and digit counts, the maximum word length when the word
+// list has longer words, and any character choice which is fixed rather than
+// random.
+func (s *DefaultEntropyService) raisableKeys() []string {
+	keys := []string{
+		option.ConfigKeyNumWords,
+		option.ConfigKeyPaddingDigitsBefore,
+		option.ConfigKeyPaddingDigitsAfter,
+	}
+
+	if s.longer {
+		keys = append(keys, option.ConfigKeyWordLengthMax)
+	}
+
+	if s.cfg.CaseTransform != option.CaseTransformRandom {
+		keys = append(keys, option.ConfigKeyCaseTransform)
+	}
+
+	switch {
+	case s.cfg.SeparatorCharacter != option.SeparatorCharacterRandom:
+		keys = append(keys, option.ConfigKeySeparatorCharacter)
+	case s.cfg.SeparatorMode != option.SeparatorModePerGap:
+		keys = append(keys, option.ConfigKeySeparatorMode)
+	}
+
+	if s.cfg.PaddingType != option.PaddingTypeNone && s.cfg.PaddingCharacter != option.PaddingCharacterRandom {
+		keys = append(keys, option.ConfigKeyPaddingCharacter)
+	}
+
+	if s.cfg.CharacterSubstitutionMode != option.CharacterSubstitutionModeRandom && minSubstitutable(s.cfg, s.wordList) > 0 {
+		keys = append(keys, option.ConfigKeyCharacterSubstitutionMode)
+	}
+
+	if s.overflows() {
+		keys = append(keys, option.ConfigKeyPadToLength)
+	}
+
+	return keys
+}
+
+// Returns the entropy for an attacker who knows the configuration and the
+// word list, summing the contribution of every random choice the pipeline
+// makes.
+func (s *DefaultEntropyService) seen() float64 {
+	bits := s.wordBits
+	bits += s.caseTransformEntropy()
+	bits += s.substitutionEntropy()
+	bits += s.separatorEntropy()
+	bits += float64(s.cfg.PaddingDigitsBefore+s.cfg.PaddingDigitsAfter) * math.Log2(float64(numPaddingDigits(s.cfg)))
+	bits += s.paddingCharacterEntropy()
+
+	// Truncating or rejecting passwords longer than pad_to_length leaves no
+	// more passwords than brute forcing that many characters, and truncation
+	// can cut every choice after it out of the password
+	if s.overflows() {
+		bits = min(bits, float64(s.cfg.PadToLength)*math.Log2(float64(s.characterPoolSize())))
+	}
+
+	return bits
+}
+
+// Reports whether ADAPTIVE padding can truncate or reject a password, which it
+// does when the password before padding can be longer than pad_to_length.
+func (s *DefaultEntropyService) overflows() bool {
+	if s.cfg.PaddingType != option.PaddingTypeAdaptive || s.cfg.PadToLength == 0 {
+		return false
+	}
+
+	_, coreMax := s.coreLengthRange()
+
+	return coreMax > s.cfg.PadToLength
+}
+
+// Checks that some passwords fit in pad_to_length when padding_overflow is
+// REJECT, as Generate draws those longer than it again. It returns an error
+// wrapping ErrPadToLengthExceeded if none can.
+func (s *DefaultEntropyService) checkPadToLength() error {
+	if s.cfg.PaddingType != option.PaddingTypeAdaptive || s.cfg.PadToLength == 0 {
+		return nil
+	}
+
+	if s.cfg.PaddingOverflow != option.PaddingOverflowReject {
+		return nil
+	}
+
+	if coreMin, _ := s.coreLengthRange(); coreMin > s.cfg.PadToLength {
+		return fmt.Errorf(
+			"%w: passwords are at least %d characters, %s is %d and %s is %s",
+			ErrPadToLengthExceeded,
+			coreMin,
+			option.ConfigKeyPadToLength,
+			s.cfg.PadToLength,
+			option.ConfigKeyPaddingOverflow,
+			option.PaddingOverflowReject,
+		)
+	}
+
+	return nil
+}
+
+// Returns the entropy added by words drawn from a single word list. Words
+// drawn with unique_words set exclude every word in the group of each word
+// already drawn, so the entropy is that of the least likely draw: the one
+// which takes the largest groups first and leaves the fewest words for the
+// words after it.
+func wordListEntropy(cfg *config.Settings, wordList []string) float64 {
+	if !cfg.UniqueWords {
+		return float64(cfg.NumWords) * math.Log2(float64(len(wordList)))
+	}
+
+	groups := newWordGroups(wordList, cfg.UniqueWordPrefixLength)
+	sizes := make([]int, groups.count())
+	for i := range sizes {
+		sizes[i] = groups.size(i)
+	}
+	slices.Sort(sizes)
+	slices.Reverse(sizes)
+
+	bits := 0.0
+	remaining := len(wordList)
+	for i := range cfg.NumWords {
+		bits += math.Log2(float64(remaining))
+		remaining -= sizes[i]
+	}
+
+	return bits
+}
+
+// Returns the entropy added by the case transformation. RANDOM adds a bit per
+// word and ALTERNATE adds a single bit, as xkpasswd.net counts them; every
+// other transformation is deterministic.
+func (s *DefaultEntropyService) caseTransformEntropy() float64 {
+	switch s.cfg.CaseTransform {
+	case option.CaseTransformRandom:
+		return float64(s.cfg.NumWords)
+	case option.CaseTransformAlternate:
+		return 1
+	}
+
+	return 0
+}
+
+// Returns the entropy added by character substitutions made at random, from
+// the fewest characters a password can have to substitute. Each adds a bit
+// with RANDOM, and AT_LEAST_ONE rules out substituting none of them; ALWAYS
+// is deterministic.
+func (s *DefaultEntropyService) substitutionEntropy() float64 {
+	n := s.cfg.NumWords * minSubstitutable(s.cfg, s.wordList)
+	if n == 0 {
+		return 0
+	}
+
+	switch s.cfg.CharacterSubstitutionMode {
+	case option.CharacterSubstitutionModeRandom:
+		return float64(n)
+	case option.CharacterSubstitutionModeAtLeastOne:
+		return math.Log2(math.Exp2(float64(n)) - 1)
+	}
+
+	return 0
+}
+
+// Returns the entropy added by a random separator character, which is only
+// present when there is a gap between words or digits to separate. With
+// separator_mode PER_GAP every gap adds its own draw.
+func (s *DefaultEntropyService) separatorEntropy() float64 {
+	if s.cfg.SeparatorCharacter != option.SeparatorCharacterRandom {
+		return 0
+	}
+
+	gaps := s.numSeparators()
+	if gaps == 0 {
+		return 0
+	}
+
+	bits := math.Log2(float64(len(effectiveAlphabet(s.cfg, s.cfg.SeparatorAlphabet))))
+	if s.cfg.SeparatorMode == option.SeparatorModePerGap {
+		return float64(gaps) * bits
+	}
+
+	return bits
+}
+
+// Returns the number of separators in a password: one between each pair of
+// words, and one next to the padding digits on each side which has them.
+func (s *DefaultEntropyService) numSeparators() int {
+	seps := max(s.cfg.NumWords-1, 0)
+	if s.cfg.PaddingDigitsBefore > 0 {
+		seps++
+	}
+	if s.cfg.PaddingDigitsAfter > 0 {
+		seps++
+	}
+
+	return seps
+}
+
+// Returns the entropy added by a random padding character. Adaptive padding
+// only counts when every password is short enough to be padded.
+func (s *DefaultEntropyService) paddingCharacterEntropy() float64 {
+	if s.cfg.PaddingCharacter != option.PaddingCharacterRandom {
+		return 0
+	}
+
+	switch s.cfg.PaddingType {
+	case option.PaddingTypeFixed:
+		if s.cfg.PaddingCharactersBefore+s.cfg.PaddingCharactersAfter == 0 {
+			return 0
+		}
+	case option.PaddingTypeAdaptive:
+		_, coreMax := s.coreLengthRange()
+		if coreMax >= s.cfg.PadToLength {
+			return 0
+		}
+	default:
+		return 0
+	}
+
+	return math.Log2(float64(len(effectiveAlphabet(s.cfg, s.cfg.SymbolAlphabet))))
+}
+
+// Returns the minimum and maximum length of a password in runes.
+func (s *DefaultEntropyService) lengthRange() (int, int) {
+	coreMin, coreMax := s.coreLengthRange()
+
+	switch s.cfg.PaddingType {
+	case option.PaddingTypeFixed:
+		padMin, padMax := s.paddingCharacterLengthRange()
+		count := s.cfg.PaddingCharactersBefore + s.cfg.PaddingCharactersAfter
+
+		return coreMin + count*padMin, coreMax + count*padMax
+	case option.PaddingTypeAdaptive:
+		// Longer passwords are truncated or rejected, and shorter ones padded
+		// if there is a padding character
+		if s.cfg.PadToLength == 0 {
+			return coreMin, coreMax
+		}
+
+		if _, padMax := s.paddingCharacterLengthRange(); padMax == 0 {
+			return min(coreMin, s.cfg.PadToLength), min(coreMax, s.cfg.PadToLength)
+		}
+
+		return s.cfg.PadToLength, s.cfg.PadToLength
+	}
+
+	return coreMin, coreMax
+}
+
+// Returns the minimum and maximum length in runes of a password before symbol
+// padding is applied: the words, the separators between them, and the padding
+// digits along with the separators which remain next to them.
+func (s *DefaultEntropyService) coreLengthRange() (int, int) {
+	wordMin, wordMax := s.wordMin, s.wordMax
+	sepMin, sepMax := s.separatorLengthRange()
+
+	seps := s.numSeparators()
+	digits := s.cfg.PaddingDigitsBefore + s.cfg.PaddingDigitsAfter
+
+	return s.cfg.NumWords*wordMin + seps*sepMin + digits, s.cfg.NumWords*wordMax + seps*sepMax + digits
+}
+
+// Returns the minimum and maximum length in runes of the separator character.
+func (s *DefaultEntropyService) separatorLengthRange() (int, int) {
+	if s.cfg.SeparatorCharacter == option.SeparatorCharacterRandom {
+		return wordLengthRange(effectiveAlphabet(s.cfg, s.cfg.SeparatorAlphabet))
+	}
+
+	n := utf8.RuneCountInString(s.cfg.SeparatorCharacter)
+
+	return n, n
+}
+
+// Returns the minimum and maximum length in runes of the padding character.
+func (s *DefaultEntropyService) paddingCharacterLengthRange() (int, int) {
+	if s.cfg.PaddingCharacter == option.PaddingCharacterRandom {
+		return wordLengthRange(effectiveAlphabet(s.cfg, s.cfg.SymbolAlphabet))
+	}
+
+	n := utf8.RuneCountInString(s.cfg.PaddingCharacter)
+
+	return n, n
+}
+
+// Returns the size of the pool of characters an attacker would have to search
+// to brute force a password, based on the character classes it can contain.
+func (s *DefaultEntropyService) characterPoolSize() int {
+	lower, upper, digit, symbol := s.wordCharacterClasses()
+
+	if len(s.cfg.CharacterSubstitutions) > 0 {
+		subLower, subUpper, subDigit, subSymbol := characterClasses(slices.Collect(maps.Values(s.cfg.CharacterSubstitutions)))
+		lower, upper, digit, symbol = lower || subLower, upper || subUpper, digit || subDigit, symbol || subSymbol
+	}
+
+	if s.cfg.PaddingDigitsBefore+s.cfg.PaddingDigitsAfter > 0 {
+		digit = true
+	}
+
+	if _, sepMax := s.separatorLengthRange(); sepMax > 0 {
+		symbol = true
+	}
+
+	if _, padMax := s.paddingCharacterLengthRange(); padMax > 0 {
+		switch s.cfg.PaddingType {
+		case option.PaddingTypeFixed:
+			symbol = symbol || s.cfg.PaddingCharactersBefore+s.cfg.PaddingCharactersAfter > 0
+		case option.PaddingTypeAdaptive:
+			symbol = symbol || s.cfg.PadToLength > 0
+		}
+	}
+
+	return poolSize(lower, upper, digit, symbol)
+}
+
+// Returns the size of the pool of characters made of the given character
+// classes.
+func poolSize(lower, upper, digit, symbol bool) int {
+	size := 0
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{
+		{lower, poolSizeLower},
+		{upper, poolSizeUpper},
+		{digit, poolSizeDigit},
+		{symbol, poolSizeSymbol},
+	} {
+		if class.present {
+			size += class.size
+		}
+	}
+
+	return size
+}
+
+// Reports which character classes the words can contain once the case
+// transformation has been applied.
+func (s *DefaultEntropyService) wordCharacterClasses() (lower, upper, digit, symbol bool) {
+	lower, upper, digit, symbol = characterClasses(s.wordList)
+
+	letters := lower || upper
+	switch s.cfg.CaseTransform {
+	case option.CaseTransformNone, "":
+	case option.CaseTransformLower:
+		lower, upper = letters, false
+	case option.CaseTransformUpper:
+		lower, upper = false, letters
+	default:
+		lower, upper = letters, letters
+	}
+
+	return lower, upper, digit, symbol
+}
+
+// Reports which character classes the characters of the strings are in.
+func characterClasses(strs []string) (lower, upper, digit, symbol bool) {
+	for _, str := range strs {
+		for _, r := range str {
+			switch {
+			case unicode.IsLower(r):
+				lower = true
+			case unicode.IsUpper(r):
+				upper = true
+			case unicode.IsDigit(r):
+				digit = true
+			default:
+				symbol = true
+			}
+		}
+	}
+
+	return lower, upper, digit, symbol
+}
+
+// Returns the length in runes of the shortest and longest elements of the
+// slice.
+func wordLengthRange(s []string) (int, int) {
+	if len(s) == 0 {
+		return 0, 0
+	}
+
+	minLen, maxLen := math.MaxInt, 0
+	for _, w := range s {
+		n := utf8.RuneCountInString(w)
+		minLen = min(minLen, n)
+		maxLen = max(maxLen, n)
+	}
+
+	return minLen, maxLen
+}
+
+// Checks the configuration of the DefaultEntropyService for correctness. It
+// ensures there are words and alphabets to draw from. Returns an error if the
+// configuration is invalid.
+func (s *DefaultEntropyService) validate() error {
+	if len(s.wordList) == 0 {
+		return fmt.Errorf("%s cannot be empty", option.ConfigKeyWordList)
+	}
+
+	if s.cfg.NumWords < 1 {
+		return fmt.Errorf("%s must be greater than or equal to 1", option.ConfigKeyNumWords)
+	}
+
+	if s.cfg.SeparatorCharacter == option.SeparatorCharacterRandom && len(effectiveAlphabet(s.cfg, s.cfg.SeparatorAlphabet)) == 0 {
+		return fmt.Errorf("%s cannot be empty", option.ConfigKeySeparatorAlphabet)
+	}
+
+	if s.cfg.PaddingCharacter == option.PaddingCharacterRandom && len(effectiveAlphabet(s.cfg, s.cfg.SymbolAlphabet)) == 0 {
+		return fmt.Errorf("%s cannot be empty", option.ConfigKeySymbolAlphabet)
+	}
+
+	if s.cfg.UniqueWords {
+		if n := newWordGroups(s.wordList, s.cfg.UniqueWordPrefixLength).count(); n < s.cfg.NumWords {
+			return fmt.Errorf(
+				"not enough distinct words in %s for a %s of %d with %s set, found %d",
+				option.ConfigKeyWordList,
+				option.ConfigKeyNumWords,
+				s.cfg.NumWords,
+				option.ConfigKeyUniqueWords,
+				n,
+			)
+		}
+	}
+
+	if s.cfg.MinEntropyBits < 0 {
+		return fmt.Errorf("%s must be greater than or equal to 0", option.ConfigKeyMinEntropyBits)
+	}
+
+	return nil
+}
@@ -0,0 +1,405 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"github.com/eljamo/libpass/v8/asset"
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+const entropyTolerance = 1e-9
+
+// makeEntropyTestWordList returns n distinct lowercase words, each 4 letters
+// long, so tests can pick list sizes which give whole numbers of bits.
+func makeEntropyTestWordList(n int) []string {
+	wl := make([]string, n)
+	for i := range n {
+		b := []byte("aaaa")
+		for j, v := len(b)-1, i; j >= 0; j, v = j-1, v/26 {
+			b[j] = byte('a' + v%26)
+		}
+		wl[i] = string(b)
+	}
+
+	return wl
+}
+
+func TestNewEntropyService(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		cfg      *config.Settings
+		wordList []string
+		wantErr  bool
+	}{
+		{
+			name:     "Valid configuration",
+			cfg:      &config.Settings{NumWords: 3, SeparatorCharacter: "-"},
+			wordList: []string{"word"},
+			wantErr:  false,
+		},
+		{
+			name:     "Invalid configuration - empty word list",
+			cfg:      &config.Settings{NumWords: 3, SeparatorCharacter: "-"},
+			wordList: nil,
+			wantErr:  true,
+		},
+		{
+			name:     "Invalid configuration - no words",
+			cfg:      &config.Settings{NumWords: 0, SeparatorCharacter: "-"},
+			wordList: []string{"word"},
+			wantErr:  true,
+		},
+		{
+			name:     "Invalid configuration - empty separator alphabet",
+			cfg:      &config.Settings{NumWords: 3, SeparatorCharacter: option.SeparatorCharacterRandom},
+			wordList: []string{"word"},
+			wantErr:  true,
+		},
+		{
+			name:     "Invalid configuration - empty symbol alphabet",
+			cfg:      &config.Settings{NumWords: 3, PaddingCharacter: option.PaddingCharacterRandom},
+			wordList: []string{"word"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := NewEntropyService(tt.cfg, tt.wordList)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewEntropyService() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEntropyCalculate(t *testing.T) {
+	t.Parallel()
+
+	wordList := makeEntropyTestWordList(1024)
+	alphabet := []string{"!", "@", "$", "%"}
+
+	tests := []struct {
+		name string
+		cfg  *config.Settings
+		want Entropy
+	}{
+		{
+			name: "Words only",
+			cfg: &config.Settings{
+				NumWords: 3, CaseTransform: option.CaseTransformLower, SeparatorCharacter: "",
+				PaddingType: option.PaddingTypeNone,
+			},
+			want: Entropy{BlindMin: 12 * math.Log2(26), BlindMax: 12 * math.Log2(26), Seen: 30},
+		},
+		{
+			name: "Random case adds a bit per word",
+			cfg: &config.Settings{
+				NumWords: 3, CaseTransform: option.CaseTransformRandom, SeparatorCharacter: "",
+				PaddingType: option.PaddingTypeNone,
+			},
+			want: Entropy{BlindMin: 12 * math.Log2(52), BlindMax: 12 * math.Log2(52), Seen: 33},
+		},
+		{
+			name: "Alternate case adds a single bit",
+			cfg: &config.Settings{
+				NumWords: 3, CaseTransform: option.CaseTransformAlternate, SeparatorCharacter: "",
+				PaddingType: option.PaddingTypeNone,
+			},
+			want: Entropy{BlindMin: 12 * math.Log2(52), BlindMax: 12 * math.Log2(52), Seen: 31},
+		},
+		{
+			name: "Random substitutions add a bit per substitutable character",
+			cfg: &config.Settings{
+				NumWords: 3, CaseTransform: option.CaseTransformLower, SeparatorCharacter: "",
+				CharacterSubstitutions:    map[string]string{"a": "4"},
+				CharacterSubstitutionMode: option.CharacterSubstitutionModeRandom,
+				PaddingType:               option.PaddingTypeNone,
+			},
+			// Every word contains at least one a
+			want: Entropy{BlindMin: 12 * math.Log2(26+10), BlindMax: 12 * math.Log2(26+10), Seen: 33},
+		},
+		{
+			name: "At least one substitution rules out none",
+			cfg: &config.Settings{
+				NumWords: 3, CaseTransform: option.CaseTransformLower, SeparatorCharacter: "",
+				CharacterSubstitutions:    map[string]string{"a": "4"},
+				CharacterSubstitutionMode: option.CharacterSubstitutionModeAtLeastOne,
+				PaddingType:               option.PaddingTypeNone,
+			},
+			want: Entropy{BlindMin: 12 * math.Log2(26+10), BlindMax: 12 * math.Log2(26+10), Seen: 30 + math.Log2(7)},
+		},
+		{
+			name: "Substitutions made always add nothing",
+			cfg: &config.Settings{
+				NumWords: 3, CaseTransform: option.CaseTransformLower, SeparatorCharacter: "",
+				CharacterSubstitutions: map[string]string{"a": "@"},
+				PaddingType:            option.PaddingTypeNone,
+			},
+			want: Entropy{BlindMin: 12 * math.Log2(26+33), BlindMax: 12 * math.Log2(26+33), Seen: 30},
+		},
+		{
+			name: "Random separator and padding digits",
+			cfg: &config.Settings{
+				NumWords: 3, CaseTransform: option.CaseTransformLower,
+				SeparatorCharacter: option.SeparatorCharacterRandom, SeparatorAlphabet: alphabet,
+				PaddingDigitsBefore: 2, PaddingDigitsAfter: 1, PaddingType: option.PaddingTypeNone,
+			},
+			// 12 word runes, 4 separators and 3 digits
+			want: Entropy{
+				BlindMin: 19 * math.Log2(26+10+33),
+				BlindMax: 19 * math.Log2(26+10+33),
+				Seen:     30 + 2 + 3*math.Log2(10),
+			},
+		},
+		{
+			name: "Per gap random separators add a draw for each gap",
+			cfg: &config.Settings{
+				NumWords: 3, CaseTransform: option.CaseTransformLower,
+				SeparatorCharacter: option.SeparatorCharacterRandom, SeparatorAlphabet: alphabet,
+				SeparatorMode: option.SeparatorModePerGap, PaddingDigitsBefore: 2, PaddingDigitsAfter: 1,
+				PaddingType: option.PaddingTypeNone,
+			},
+			want: Entropy{
+				BlindMin: 19 * math.Log2(26+10+33),
+				BlindMax: 19 * math.Log2(26+10+33),
+				Seen:     30 + 4*2 + 3*math.Log2(10),
+			},
+		},
+		{
+			name: "Multi-character separator",
+			cfg: &config.Settings{
+				NumWords: 3, CaseTransform: option.CaseTransformLower, SeparatorCharacter: " - ",
+				PaddingType: option.PaddingTypeNone,
+			},
+			// 12 word runes and 2 separators of 3 runes
+			want: Entropy{BlindMin: 18 * math.Log2(26+33), BlindMax: 18 * math.Log2(26+33), Seen: 30},
+		},
+		{
+			name: "Avoid ambiguous leaves out look-alike separators and digits",
+			cfg: &config.Settings{
+				AvoidAmbiguous: true, NumWords: 3, CaseTransform: option.CaseTransformLower,
+				SeparatorCharacter: option.SeparatorCharacterRandom, SeparatorAlphabet: append([]string{"|"}, alphabet...),
+				PaddingDigitsBefore: 2, PaddingDigitsAfter: 1, PaddingType: option.PaddingTypeNone,
+			},
+			// | is left out of the separators and 0 and 1 out of the digits
+			want: Entropy{
+				BlindMin: 19 * math.Log2(26+10+33),
+				BlindMax: 19 * math.Log2(26+10+33),
+				Seen:     30 + 2 + 3*3,
+			},
+		},
+		{
+			name: "Fixed random padding",
+			cfg: &config.Settings{
+				NumWords: 2, CaseTransform: option.CaseTransformUpper, SeparatorCharacter: "-",
+				PaddingType: option.PaddingTypeFixed, PaddingCharacter: option.PaddingCharacterRandom,
+				SymbolAlphabet: alphabet, PaddingCharactersBefore: 2, PaddingCharactersAfter: 2,
+			},
+			// 8 word runes, 1 separator and 4 padding characters
+			want: Entropy{BlindMin: 13 * math.Log2(26+33), BlindMax: 13 * math.Log2(26+33), Seen: 22},
+		},
+		{
+			name: "Adaptive padding to a longer length",
+			cfg: &config.Settings{
+				NumWords: 2, CaseTransform: option.CaseTransformLower, SeparatorCharacter: "-",
+				PaddingType: option.PaddingTypeAdaptive, PaddingCharacter: option.PaddingCharacterRandom,
+				SymbolAlphabet: alphabet, PadToLength: 20,
+			},
+			want: Entropy{BlindMin: 20 * math.Log2(26+33), BlindMax: 20 * math.Log2(26+33), Seen: 22},
+		},
+		{
+			name: "Adaptive padding shorter than the password truncates it and adds nothing",
+			cfg: &config.Settings{
+				NumWords: 2, CaseTransform: option.CaseTransformLower, SeparatorCharacter: "-",
+				PaddingType: option.PaddingTypeAdaptive, PaddingCharacter: option.PaddingCharacterRandom,
+				SymbolAlphabet: alphabet, PadToLength: 5,
+			},
+			// 20 bits of words fit in 5 characters
+			want: Entropy{BlindMin: 5 * math.Log2(26+33), BlindMax: 5 * math.Log2(26+33), Seen: 20},
+		},
+		{
+			name: "Truncation caps the seen entropy at the blind entropy",
+			cfg: &config.Settings{
+				NumWords: 2, CaseTransform: option.CaseTransformLower, SeparatorCharacter: "-",
+				PaddingType: option.PaddingTypeAdaptive, PaddingCharacter: option.PaddingCharacterRandom,
+				SymbolAlphabet: alphabet, PadToLength: 3,
+			},
+			want: Entropy{BlindMin: 3 * math.Log2(26+33), BlindMax: 3 * math.Log2(26+33), Seen: 3 * math.Log2(26+33)},
+		},
+		{
+			name: "Rejection caps the seen entropy at the blind entropy",
+			cfg: &config.Settings{
+				NumWords: 2, CaseTransform: option.CaseTransformLower, SeparatorCharacter: "-",
+				PaddingType: option.PaddingTypeAdaptive, PaddingCharacter: option.PaddingCharacterRandom,
+				SymbolAlphabet: alphabet, PadToLength: 3, PaddingOverflow: option.PaddingOverflowReject,
+			},
+			want: Entropy{BlindMin: 3 * math.Log2(26+33), BlindMax: 3 * math.Log2(26+33), Seen: 3 * math.Log2(26+33)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc, err := NewEntropyService(tt.cfg, wordList)
+			if err != nil {
+				t.Fatalf("NewEntropyService() error = %v", err)
+			}
+
+			got, err := svc.Calculate()
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+
+			if math.Abs(got.BlindMin-tt.want.BlindMin) > entropyTolerance ||
+				math.Abs(got.BlindMax-tt.want.BlindMax) > entropyTolerance ||
+				math.Abs(got.Seen-tt.want.Seen) > entropyTolerance {
+				t.Errorf("Calculate() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntropyCalculateVariableWordLengths(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Settings{
+		NumWords: 2, CaseTransform: option.CaseTransformNone, SeparatorCharacter: "-",
+		PaddingType: option.PaddingTypeNone,
+	}
+
+	svc, err := NewEntropyService(cfg, []string{"ab", "Cdef"})
+	if err != nil {
+		t.Fatalf("NewEntropyService() error = %v", err)
+	}
+
+	got, err := svc.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+
+	pool := math.Log2(26 + 26 + 33)
+	want := Entropy{BlindMin: 5 * pool, BlindMax: 9 * pool, Seen: 2}
+	if math.Abs(got.BlindMin-want.BlindMin) > entropyTolerance ||
+		math.Abs(got.BlindMax-want.BlindMax) > entropyTolerance ||
+		math.Abs(got.Seen-want.Seen) > entropyTolerance {
+		t.Errorf("Calculate() = %+v, want %+v", *got, want)
+	}
+}
+
+func TestEntropyCalculateUniqueWords(t *testing.T) {
+	t.Parallel()
+
+	wordList := makeEntropyTestWordList(1024)
+
+	tests := []struct {
+		name      string
+		prefixLen int
+		want      float64
+	}{
+		{
+			name: "Whole words",
+			want: math.Log2(1024) + math.Log2(1023) + math.Log2(1022),
+		},
+		{
+			// Groups of 26 words share their first 3 letters, the last group has 10
+			name:      "Shared prefixes",
+			prefixLen: 3,
+			want:      math.Log2(1024) + math.Log2(998) + math.Log2(972),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &config.Settings{
+				NumWords: 3, CaseTransform: option.CaseTransformLower, PaddingType: option.PaddingTypeNone,
+				UniqueWords: true, UniqueWordPrefixLength: tt.prefixLen,
+			}
+
+			svc, err := NewEntropyService(cfg, wordList)
+			if err != nil {
+				t.Fatalf("NewEntropyService() error = %v", err)
+			}
+
+			got, err := svc.Calculate()
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+
+			if math.Abs(got.Seen-tt.want) > entropyTolerance {
+				t.Errorf("Calculate() Seen = %v, want %v", got.Seen, tt.want)
+			}
+		})
+	}
+
+	cfg := &config.Settings{NumWords: 3, UniqueWords: true}
+	if _, err := NewEntropyService(cfg, []string{"word", "Word"}); err == nil {
+		t.Error("NewEntropyService() error = nil, want an error for too few distinct words")
+	}
+}
+
+// newPresetEntropyService returns the EntropyService for the generator the
+// preset configures.
+func newPresetEntropyService(t *testing.T, preset string, cfg *config.Settings) EntropyService {
+	t.Helper()
+
+	if cfg.Generator == option.GeneratorCharacters {
+		svc, err := NewCharacterPasswordGeneratorService(cfg, NewRNGService())
+		if err != nil {
+			t.Fatalf("NewCharacterPasswordGeneratorService(%q) error = %v", preset, err)
+		}
+
+		return svc
+	}
+
+	wls, err := NewWordListService(cfg, NewRNGService())
+	if err != nil {
+		t.Fatalf("NewWordListService(%q) error = %v", preset, err)
+	}
+
+	svc, err := NewEntropyService(cfg, wls.WordList())
+	if err != nil {
+		t.Fatalf("NewEntropyService(%q) error = %v", preset, err)
+	}
+
+	return svc
+}
+
+func TestEntropyCalculatePresets(t *testing.T) {
+	t.Parallel()
+
+	for _, preset := range asset.Presets.Names() {
+		t.Run(preset, func(t *testing.T) {
+			t.Parallel()
+
+			pm, err := asset.GetJSONPreset(preset)
+			if err != nil {
+				t.Fatalf("GetJSONPreset(%q) error = %v", preset, err)
+			}
+
+			cfg, err := config.New(pm)
+			if err != nil {
+				t.Fatalf("config.New(%q) error = %v", preset, err)
+			}
+
+			svc := newPresetEntropyService(t, preset, cfg)
+
+			got, err := svc.Calculate()
+			if err != nil {
+				t.Fatalf("Calculate(%q) error = %v", preset, err)
+			}
+
+			if got.Seen <= 0 || got.BlindMin <= 0 || got.BlindMin > got.BlindMax {
+				t.Errorf("Calculate(%q) = %+v, want positive entropy with BlindMin <= BlindMax", preset, *got)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+// The embedded word list each part of speech draws its words from
+var partOfSpeechWordLists = map[string]string{
+	option.PartOfSpeechAdjective: option.WordListAdjectives,
+	option.PartOfSpeechAdverb:    option.WordListAdverbs,
+	option.PartOfSpeechNoun:      option.WordListNouns,
+	option.PartOfSpeechVerb:      option.WordListVerbs,
+}
+
+// GrammaticalWordListService implements the WordListService interface,
+// drawing each word from the word list of its part of speech so passphrases
+// follow a grammatical structure, e.g. purple-otter-juggles-quietly, which is
+// easier to remember than a random sequence of words. The structure is set in
+// grammar, one part of speech per word, and repeats option.DefaultGrammar
+// when grammar isn't set. The words are then transformed, separated and
+// padded like any other words.
+type GrammaticalWordListService struct {
+	*CompositeWordListService
+	grammar []string
+}
+
+// Creates a new instance of GrammaticalWordListService from the grammar and
+// num_words settings, which are used instead of word_list and word_list_file.
+// Each part of speech's word list is filtered in the same way as a single
+// word list. It returns an error if the configuration is invalid or a word
+// list has no words left once filtered.
+func NewGrammaticalWordListService(cfg *config.Settings, rngSvc RNGService) (*GrammaticalWordListService, error) {
+	grammar, err := resolveGrammar(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	slots := make([]string, len(grammar))
+	for i, pos := range grammar {
+		slots[i] = partOfSpeechWordLists[pos]
+	}
+
+	// The parts of speech are drawn as slots of a composite word list
+	slotCfg := *cfg
+	slotCfg.WordListSlots = slots
+
+	composite, err := NewCompositeWordListService(&slotCfg, rngSvc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GrammaticalWordListService{CompositeWordListService: composite, grammar: grammar}, nil
+}
+
+// Returns the part of speech of each word, from grammar if it is set and by
+// repeating option.DefaultGrammar for num_words otherwise. It returns an
+// error if a part of speech is unknown, there isn't one for every word, or
+// word_list_weights or word_list_slots is set.
+func resolveGrammar(cfg *config.Settings) ([]string, error) {
+	for _, key := range []struct {
+		name string
+		set  bool
+	}{
+		{option.ConfigKeyWordListSlots, len(cfg.WordListSlots) > 0},
+		{option.ConfigKeyWordListWeights, len(cfg.WordListWeights) > 0},
+	} {
+		if key.set {
+			return nil, fmt.Errorf("%s cannot be set when %s is %s", key.name, option.ConfigKeyGenerator, option.GeneratorGrammatical)
+		}
+	}
+
+	if len(cfg.Grammar) == 0 {
+		grammar := make([]string, max(cfg.NumWords, 0))
+		for i := range grammar {
+			grammar[i] = option.DefaultGrammar[i%len(option.DefaultGrammar)]
+		}
+
+		return grammar, nil
+	}
+
+	for _, pos := range cfg.Grammar {
+		if !slices.Contains(option.PartsOfSpeech, pos) {
+			return nil, fmt.Errorf("invalid %s value (%s)", option.ConfigKeyGrammar, pos)
+		}
+	}
+
+	if len(cfg.Grammar) != cfg.NumWords {
+		return nil, fmt.Errorf(
+			"%s must have one part of speech for each word, %s is %d but %d were given",
+			option.ConfigKeyGrammar,
+			option.ConfigKeyNumWords,
+			cfg.NumWords,
+			len(cfg.Grammar),
+		)
+	}
+
+	return slices.Clone(cfg.Grammar), nil
+}
+
+// Grammar returns the part of speech of each word in a password.
+func (s *GrammaticalWordListService) Grammar() []string {
+	return slices.Clone(s.grammar)
+}
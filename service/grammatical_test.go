@@ -0,0 +1,208 @@
+package service
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/eljamo/libpass/v8/asset"
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+func newGrammaticalTestSettings() *config.Settings {
+	return &config.Settings{
+		Generator: option.GeneratorGrammatical, NumWords: 4,
+		WordLengthMin: 3, WordLengthMax: 10,
+		CaseTransform:      option.CaseTransformLower,
+		SeparatorCharacter: "-",
+		PaddingType:        option.PaddingTypeNone, NumPasswords: 1,
+	}
+}
+
+func grammaticalTestWordList(t *testing.T, pos string) []string {
+	t.Helper()
+
+	words, err := asset.GetWordListView(partOfSpeechWordLists[pos], 3, 10)
+	if err != nil {
+		t.Fatalf("GetWordListView() error = %v", err)
+	}
+
+	return words
+}
+
+func TestGrammaticalWordListServiceGetWords(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		numWords int
+		grammar  []string
+		want     []string
+	}{
+		{
+			name:     "Default grammar",
+			numWords: 4,
+			want:     option.DefaultGrammar,
+		},
+		{
+			name:     "Default grammar repeated",
+			numWords: 6,
+			want: []string{
+				option.PartOfSpeechAdjective, option.PartOfSpeechNoun, option.PartOfSpeechVerb,
+				option.PartOfSpeechAdverb, option.PartOfSpeechAdjective, option.PartOfSpeechNoun,
+			},
+		},
+		{
+			name:     "Custom grammar",
+			numWords: 3,
+			grammar:  []string{option.PartOfSpeechNoun, option.PartOfSpeechVerb, option.PartOfSpeechNoun},
+			want:     []string{option.PartOfSpeechNoun, option.PartOfSpeechVerb, option.PartOfSpeechNoun},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := newGrammaticalTestSettings()
+			cfg.NumWords = tt.numWords
+			cfg.Grammar = tt.grammar
+
+			svc, err := NewGrammaticalWordListService(cfg, NewSeededRNGService([]byte("grammatical")))
+			if err != nil {
+				t.Fatalf("NewGrammaticalWordListService() error = %v", err)
+			}
+
+			if got := svc.Grammar(); !slices.Equal(got, tt.want) {
+				t.Fatalf("Grammar() = %v, want %v", got, tt.want)
+			}
+
+			for range presetContractIterations {
+				words, err := svc.GetWords()
+				if err != nil {
+					t.Fatalf("GetWords() error = %v", err)
+				}
+
+				if len(words) != len(tt.want) {
+					t.Fatalf("GetWords() returned %d words, want %d", len(words), len(tt.want))
+				}
+
+				for i, w := range words {
+					if !slices.Contains(grammaticalTestWordList(t, tt.want[i]), w) {
+						t.Fatalf("GetWords() word %d = %q, want a word from %s", i, w, partOfSpeechWordLists[tt.want[i]])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGrammaticalWordListServiceWordEntropy(t *testing.T) {
+	t.Parallel()
+
+	cfg := newGrammaticalTestSettings()
+	cfg.NumWords = 5
+
+	svc, err := NewGrammaticalWordListService(cfg, &mockRNGService{})
+	if err != nil {
+		t.Fatalf("NewGrammaticalWordListService() error = %v", err)
+	}
+
+	// The entropy of each slot's word list, with the adjectives used twice
+	want := 0.0
+	for _, pos := range append(slices.Clone(option.DefaultGrammar), option.PartOfSpeechAdjective) {
+		want += math.Log2(float64(len(grammaticalTestWordList(t, pos))))
+	}
+
+	if got := svc.WordEntropy(); math.Abs(got-want) > entropyTolerance {
+		t.Errorf("WordEntropy() = %v, want %v", got, want)
+	}
+
+	es, err := NewEntropyServiceFromWordList(cfg, svc)
+	if err != nil {
+		t.Fatalf("NewEntropyServiceFromWordList() error = %v", err)
+	}
+
+	got, err := es.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+
+	if math.Abs(got.Seen-want) > entropyTolerance {
+		t.Errorf("Calculate() Seen = %v, want %v", got.Seen, want)
+	}
+}
+
+func TestNewGrammaticalWordListServiceErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		modify func(cfg *config.Settings)
+	}{
+		{
+			name:   "Unknown part of speech",
+			modify: func(cfg *config.Settings) { cfg.Grammar = []string{"NOUN", "PRONOUN", "VERB", "ADVERB"} },
+		},
+		{
+			name:   "Too few parts of speech",
+			modify: func(cfg *config.Settings) { cfg.Grammar = []string{option.PartOfSpeechNoun, option.PartOfSpeechVerb} },
+		},
+		{
+			name:   "Word list slots",
+			modify: func(cfg *config.Settings) { cfg.WordListSlots = []string{"EN", "EN", "EN", "EN"} },
+		},
+		{
+			name:   "Word list weights",
+			modify: func(cfg *config.Settings) { cfg.WordListWeights = map[string]int{"EN": 1} },
+		},
+		{
+			name:   "Too few words",
+			modify: func(cfg *config.Settings) { cfg.NumWords = 1 },
+		},
+		{
+			name:   "Unique words",
+			modify: func(cfg *config.Settings) { cfg.UniqueWords = true },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := newGrammaticalTestSettings()
+			tt.modify(cfg)
+
+			if _, err := NewGrammaticalWordListService(cfg, &mockRNGService{}); err == nil {
+				t.Error("NewGrammaticalWordListService() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestGrammaticalPasswordGenerator(t *testing.T) {
+	t.Parallel()
+
+	svc, err := NewGeneratorServiceWithRNG(newGrammaticalTestSettings(), NewSeededRNGService([]byte("grammatical")))
+	if err != nil {
+		t.Fatalf("NewGeneratorServiceWithRNG() error = %v", err)
+	}
+
+	pws, err := svc.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	words := strings.Split(pws[0], "-")
+	if len(words) != len(option.DefaultGrammar) {
+		t.Fatalf("Generate() = %q, want %d words", pws[0], len(option.DefaultGrammar))
+	}
+
+	for i, w := range words {
+		if !slices.Contains(grammaticalTestWordList(t, option.DefaultGrammar[i]), w) {
+			t.Errorf("Generate() word %d = %q, want a word from %s", i, w, partOfSpeechWordLists[option.DefaultGrammar[i]])
+		}
+	}
+}
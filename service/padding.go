@@ -1,7 +1,9 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"unicode/utf8"
@@ -11,6 +13,15 @@ import (
 	"github.com/eljamo/libpass/v8/internal/validator"
 )
 
+// The number of times a password is generated again when it is longer than
+// pad_to_length and padding_overflow is REJECT, before giving up
+const padToLengthMaxAttempts int = 1000
+
+// ErrPadToLengthExceeded is returned when padding_overflow is REJECT and
+// passwords are longer than pad_to_length with ADAPTIVE padding: when every
+// password is, or every attempt to generate a shorter one fails.
+var ErrPadToLengthExceeded = errors.New("password is longer than pad_to_length")
+
 // Defines the interface for a service that provides functionality to pad a
 // slice of strings.
 type PaddingService interface {
@@ -22,15 +33,20 @@ type PaddingService interface {
 // Implements the PaddingService interface. It provides methods to add padding
 // to strings based on predefined configuration settings.
 type DefaultPaddingService struct {
-	cfg    *config.Settings
-	rngSvc RNGService
+	cfg      *config.Settings
+	rngSvc   RNGService
+	alphabet []string // The symbols a random padding character is drawn from
 }
 
 // Creates a new instance of DefaultPaddingService with the provided
 // configuration and RNGService. It returns an error if the provided
 // configuration is invalid.
 func NewPaddingService(cfg *config.Settings, rngSvc RNGService) (*DefaultPaddingService, error) {
-	svc := &DefaultPaddingService{cfg, rngSvc}
+	svc := &DefaultPaddingService{
+		cfg:      cfg,
+		rngSvc:   rngSvc,
+		alphabet: effectiveAlphabet(cfg, cfg.SymbolAlphabet),
+	}
 
 	if err := svc.validate(); err != nil {
 		return nil, err
@@ -89,7 +105,7 @@ func (s *DefaultPaddingService) digits(slice []string) ([]string, error) {
 func (s *DefaultPaddingService) generateRandomDigits(num int) ([]string, error) {
 	digits := make([]string, 0, num)
 	for range num {
-		num, err := s.rngSvc.GenerateDigit()
+		num, err := generateDigit(s.cfg, s.rngSvc)
 		if err != nil {
 			return nil, err
 		}
@@ -100,48 +116,35 @@ func (s *DefaultPaddingService) generateRandomDigits(num int) ([]string, error)
 	return digits, nil
 }
 
-// Removes separator characters from the edges of the given slice. It handles
-// both specified and random separator characters based on the configuration.
+// Removes the separators from the edges of the given slice, which remain when
+// there are no padding digits on that side. A separator is a whole element of
+// the slice, so separators several characters long, e.g. " - ", are removed
+// whole, and with separator_mode PER_GAP the separator on each edge can be
+// any element of the separator alphabet.
 func (s *DefaultPaddingService) removeEdgeSeparatorCharacter(slice []string) []string {
 	if len(slice) == 0 {
 		return slice
 	}
 
-	if s.cfg.SeparatorCharacter == option.SeparatorCharacterRandom {
-		return s.removeRandomEdgeSeparatorCharacter(slice)
-	}
-
 	start, end := 0, len(slice)
-	if slice[start] == s.cfg.SeparatorCharacter {
+	if s.isSeparator(slice[start]) {
 		start++
 	}
-	if end > start && slice[end-1] == s.cfg.SeparatorCharacter {
+	if end > start && s.isSeparator(slice[end-1]) {
 		end--
 	}
 
 	return slice[start:end]
 }
 
-// Specifically handles the removal of random edge separator characters. It is
-// used when the separator character is set to random in the configuration.
-func (s *DefaultPaddingService) removeRandomEdgeSeparatorCharacter(slice []string) []string {
-	if len(slice) == 0 {
-		return slice
-	}
-
-	if len(s.cfg.SeparatorAlphabet) == 0 {
-		return slice
-	}
-
-	start, end := 0, len(slice)
-	if validator.IsElementInSlice(s.cfg.SeparatorAlphabet, slice[start]) {
-		start++
-	}
-	if end > start && validator.IsElementInSlice(s.cfg.SeparatorAlphabet, slice[end-1]) {
-		end--
+// Reports whether the element is a separator: the separator character, or
+// an element of the separator alphabet when the separator is random.
+func (s *DefaultPaddingService) isSeparator(element string) bool {
+	if s.cfg.SeparatorCharacter == option.SeparatorCharacterRandom {
+		return validator.IsElementInSlice(s.cfg.SeparatorAlphabet, element)
 	}
 
-	return slice[start:end]
+	return element == s.cfg.SeparatorCharacter
 }
 
 // Applies symbol-based padding to the provided string as per the service
@@ -157,7 +160,7 @@ func (s *DefaultPaddingService) symbols(pw string) (string, error) {
 	case option.PaddingTypeFixed:
 		return s.fixed(pw, char)
 	case option.PaddingTypeAdaptive:
-		return s.adaptive(pw, char), nil
+		return s.adaptive(pw, char)
 	case option.PaddingTypeNone:
 		return pw, nil
 	}
@@ -169,11 +172,11 @@ func (s *DefaultPaddingService) symbols(pw string) (string, error) {
 // from the symbol alphabet if the padding character is set to random.
 func (s *DefaultPaddingService) getPaddingCharacter() (string, error) {
 	if s.cfg.PaddingCharacter == option.PaddingCharacterRandom {
-		num, err := s.rngSvc.GenerateWithMax(len(s.cfg.SymbolAlphabet))
+		num, err := s.rngSvc.GenerateWithMax(len(s.alphabet))
 		if err != nil {
 			return "", err
 		}
-		return s.cfg.SymbolAlphabet[num], nil
+		return s.alphabet[num], nil
 	}
 
 	return s.cfg.PaddingCharacter, nil
@@ -189,18 +192,35 @@ func (s *DefaultPaddingService) fixed(pw string, char string) (string, error) {
 }
 
 // Applies padding to the input string to meet a specified total length.
-// The padding is added at the end of the string and uses the provided
-// padding character.
-func (s *DefaultPaddingService) adaptive(pw string, char string) string {
+// The padding is added after the string, before it, or split between both
+// sides with any odd character after, as padding_position sets. A string
+// longer than pad_to_length is truncated to it, or returns an error wrapping
+// ErrPadToLengthExceeded when padding_overflow is REJECT. A pad_to_length of
+// 0 leaves the string unchanged.
+func (s *DefaultPaddingService) adaptive(pw string, char string) (string, error) {
 	pwLen := utf8.RuneCountInString(pw)
-	if s.cfg.PadToLength <= pwLen {
-		return pw
+	if s.cfg.PadToLength == 0 || s.cfg.PadToLength == pwLen {
+		return pw, nil
+	}
+
+	if pwLen > s.cfg.PadToLength {
+		if s.cfg.PaddingOverflow == option.PaddingOverflowReject {
+			return "", fmt.Errorf("%w: %d characters, %s is %d", ErrPadToLengthExceeded, pwLen, option.ConfigKeyPadToLength, s.cfg.PadToLength)
+		}
+
+		return string([]rune(pw)[:s.cfg.PadToLength]), nil
 	}
 
 	diff := s.cfg.PadToLength - pwLen
-	padding := strings.Repeat(char, diff)
+	before := 0
+	switch s.cfg.PaddingPosition {
+	case option.PaddingPositionBefore:
+		before = diff
+	case option.PaddingPositionBoth:
+		before = diff / 2
+	}
 
-	return pw + padding
+	return strings.Repeat(char, before) + pw + strings.Repeat(char, diff-before), nil
 }
 
 // Checks the service's configuration for any invalid values. It ensures the
@@ -229,9 +249,38 @@ func (s *DefaultPaddingService) validate() error {
 		return fmt.Errorf("%s and %s must be greater than or equal to 0", option.ConfigKeyPaddingCharactersBefore, option.ConfigKeyPaddingCharactersAfter)
 	}
 
+	if err := s.validateAdaptive(); err != nil {
+		return err
+	}
+
+	if s.cfg.PaddingType == option.PaddingTypeNone {
+		return nil
+	}
+
+	return validateUnambiguous(
+		s.cfg,
+		option.ConfigKeyPaddingCharacter,
+		s.cfg.PaddingCharacter,
+		s.cfg.PaddingCharacter == option.PaddingCharacterRandom,
+		option.ConfigKeySymbolAlphabet,
+		s.cfg.SymbolAlphabet,
+	)
+}
+
+// Checks the settings of ADAPTIVE padding: pad_to_length can't be negative,
+// and padding_overflow and padding_position must be known values if set.
+func (s *DefaultPaddingService) validateAdaptive() error {
 	if s.cfg.PadToLength < 0 {
 		return fmt.Errorf("%s must be greater than or equal to 0", option.ConfigKeyPadToLength)
 	}
 
+	if s.cfg.PaddingOverflow != "" && !slices.Contains(option.PaddingOverflows, s.cfg.PaddingOverflow) {
+		return fmt.Errorf("invalid %s value (%s)", option.ConfigKeyPaddingOverflow, s.cfg.PaddingOverflow)
+	}
+
+	if s.cfg.PaddingPosition != "" && !slices.Contains(option.PaddingPositions, s.cfg.PaddingPosition) {
+		return fmt.Errorf("invalid %s value (%s)", option.ConfigKeyPaddingPosition, s.cfg.PaddingPosition)
+	}
+
 	return nil
 }
@@ -1,9 +1,13 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"slices"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/eljamo/libpass/v8/config"
 	"github.com/eljamo/libpass/v8/config/option"
@@ -68,6 +72,16 @@ func TestNewPaddingService(t *testing.T) {
 			cfg:     &config.Settings{PadToLength: -1, PaddingType: option.PaddingTypeAdaptive},
 			wantErr: true,
 		},
+		{
+			name:    "Invalid configuration - unknown padding overflow",
+			cfg:     &config.Settings{PadToLength: 16, PaddingType: option.PaddingTypeAdaptive, PaddingOverflow: "WRAP"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid configuration - unknown padding position",
+			cfg:     &config.Settings{PadToLength: 16, PaddingType: option.PaddingTypeAdaptive, PaddingPosition: "MIDDLE"},
+			wantErr: true,
+		},
 		{
 			name: "Valid configuration - single multi-byte rune padding character",
 			cfg: &config.Settings{
@@ -294,10 +308,21 @@ func TestRemoveEdgeSeparatorCharacter(t *testing.T) {
 	}
 }
 
+func TestRemoveMultiCharacterEdgeSeparatorCharacter(t *testing.T) {
+	t.Parallel()
+
+	s := &DefaultPaddingService{cfg: &config.Settings{SeparatorCharacter: " - "}}
+	got := s.removeEdgeSeparatorCharacter([]string{" - ", "a", " - ", "b", " - "})
+	expected := []string{"a", " - ", "b"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("removeEdgeSeparatorCharacter() got = %v, expected %v", got, expected)
+	}
+}
+
 func TestRemoveRandomEdgeSeparatorCharacter(t *testing.T) {
 	t.Parallel()
 
-	cfg := &config.Settings{SeparatorCharacter: "RANDOM", SeparatorAlphabet: []string{"!", "-", "="}}
+	cfg := &config.Settings{SeparatorCharacter: "RANDOM", SeparatorAlphabet: []string{"!", "-", "=", "::"}}
 
 	tests := []struct {
 		name     string
@@ -324,6 +349,16 @@ func TestRemoveRandomEdgeSeparatorCharacter(t *testing.T) {
 			input:    []string{"-", "a", "b", "c", "-"},
 			expected: []string{"a", "b", "c"},
 		},
+		{
+			name:     "different separators at each end",
+			input:    []string{"!", "a", "-", "b", "="},
+			expected: []string{"a", "-", "b"},
+		},
+		{
+			name:     "multi-character separators at both ends",
+			input:    []string{"::", "a", "::"},
+			expected: []string{"a"},
+		},
 		{
 			name:     "empty input",
 			input:    []string{},
@@ -515,11 +550,14 @@ func TestAdaptive(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
-		name   string
-		pw     string
-		char   string
-		padLen int
-		want   string
+		name     string
+		pw       string
+		char     string
+		padLen   int
+		overflow string
+		position string
+		want     string
+		wantErr  error
 	}{
 		{
 			name:   "no padding needed",
@@ -542,6 +580,51 @@ func TestAdaptive(t *testing.T) {
 			padLen: 10,
 			want:   "**********",
 		},
+		{
+			name:     "padding before",
+			pw:       "12345",
+			char:     "*",
+			padLen:   10,
+			position: option.PaddingPositionBefore,
+			want:     "*****12345",
+		},
+		{
+			name:     "padding on both sides",
+			pw:       "12345",
+			char:     "*",
+			padLen:   10,
+			position: option.PaddingPositionBoth,
+			want:     "**12345***",
+		},
+		{
+			name:   "too long is truncated",
+			pw:     "correct-horse",
+			char:   "*",
+			padLen: 7,
+			want:   "correct",
+		},
+		{
+			name:   "multi-byte runes truncated whole",
+			pw:     "€€€€",
+			char:   "*",
+			padLen: 2,
+			want:   "€€",
+		},
+		{
+			name:     "too long is rejected",
+			pw:       "correct-horse",
+			char:     "*",
+			padLen:   7,
+			overflow: option.PaddingOverflowReject,
+			wantErr:  ErrPadToLengthExceeded,
+		},
+		{
+			name:   "zero pad to length",
+			pw:     "12345",
+			char:   "*",
+			padLen: 0,
+			want:   "12345",
+		},
 	}
 
 	for _, tt := range tests {
@@ -549,15 +632,20 @@ func TestAdaptive(t *testing.T) {
 			t.Parallel()
 
 			cfg := &config.Settings{
-				PadToLength: tt.padLen,
+				PadToLength:     tt.padLen,
+				PaddingOverflow: tt.overflow,
+				PaddingPosition: tt.position,
 			}
 
 			s, err := NewPaddingService(cfg, &mockEvenRNGService{})
 			if err != nil {
-				t.Errorf("service init error: %v", err)
+				t.Fatalf("service init error: %v", err)
 			}
 
-			got := s.adaptive(tt.pw, tt.char)
+			got, err := s.adaptive(tt.pw, tt.char)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("adaptive() error = %v, want %v", err, tt.wantErr)
+			}
 
 			if got != tt.want {
 				t.Errorf("adaptive() got = %v, want %v", got, tt.want)
@@ -565,3 +653,175 @@ func TestAdaptive(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateAdaptiveLength(t *testing.T) {
+	t.Parallel()
+
+	// The words, separators and digits are at least 24 characters, so every
+	// password is truncated
+	cfg := config.DefaultSettings()
+	cfg.NumWords = 4
+	cfg.WordLengthMin = 5
+	cfg.PaddingType = option.PaddingTypeAdaptive
+	cfg.PadToLength = 16
+
+	svc, err := NewGeneratorServiceWithRNG(cfg, NewSeededRNGService([]byte("adaptive")))
+	if err != nil {
+		t.Fatalf("NewGeneratorServiceWithRNG() error = %v", err)
+	}
+
+	pws, err := svc.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	for _, pw := range pws {
+		if n := utf8.RuneCountInString(pw); n != cfg.PadToLength {
+			t.Errorf("Generate() password %q is %d runes, want %d", pw, n, cfg.PadToLength)
+		}
+	}
+
+	// Rejecting passwords the words always make too long is caught before any
+	// are generated
+	cfg.PaddingOverflow = option.PaddingOverflowReject
+	if _, err := NewGeneratorServiceWithRNG(cfg, NewSeededRNGService([]byte("adaptive"))); !errors.Is(err, ErrPadToLengthExceeded) {
+		t.Errorf("NewGeneratorServiceWithRNG() error = %v, want %v", err, ErrPadToLengthExceeded)
+	}
+}
+
+func TestGenerateAdaptiveReject(t *testing.T) {
+	t.Parallel()
+
+	// The words, separators and digits are at most 41 characters
+	cfg := config.DefaultSettings()
+	cfg.PaddingType = option.PaddingTypeAdaptive
+	cfg.PaddingOverflow = option.PaddingOverflowReject
+	cfg.PadToLength = 41
+
+	svc, err := NewGeneratorServiceWithRNG(cfg, NewSeededRNGService([]byte("adaptive")))
+	if err != nil {
+		t.Fatalf("NewGeneratorServiceWithRNG() error = %v", err)
+	}
+
+	for range presetContractIterations {
+		pws, err := svc.Generate()
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+
+		for _, pw := range pws {
+			if n := utf8.RuneCountInString(pw); n != cfg.PadToLength {
+				t.Errorf("Generate() password %q is %d runes, want %d", pw, n, cfg.PadToLength)
+			}
+		}
+	}
+}
+
+func TestGenerateAdaptiveRejectRedraws(t *testing.T) {
+	t.Parallel()
+
+	// Three words of 4 to 8 letters with three separators and a digit are 16
+	// to 28 characters, so only the shorter passwords fit
+	cfg := config.DefaultSettings()
+	cfg.NumWords = 3
+	cfg.WordLengthMin = 4
+	cfg.WordLengthMax = 8
+	cfg.PaddingDigitsBefore = 0
+	cfg.PaddingDigitsAfter = 1
+	cfg.PaddingType = option.PaddingTypeAdaptive
+	cfg.PaddingOverflow = option.PaddingOverflowReject
+	cfg.PadToLength = 20
+
+	svc, err := NewGeneratorServiceWithRNG(cfg, NewSeededRNGService([]byte("adaptive")))
+	if err != nil {
+		t.Fatalf("NewGeneratorServiceWithRNG() error = %v", err)
+	}
+
+	for range presetContractIterations {
+		pws, err := svc.Generate()
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+
+		for _, pw := range pws {
+			if n := utf8.RuneCountInString(pw); n != cfg.PadToLength {
+				t.Errorf("Generate() password %q is %d runes, want %d", pw, n, cfg.PadToLength)
+			}
+		}
+	}
+}
+
+type mockRejectPaddingService struct {
+	rejections int // The number of passwords rejected before one is padded
+	calls      int
+}
+
+func (m *mockRejectPaddingService) Pad(password []string) (string, error) {
+	m.calls++
+	if m.calls <= m.rejections {
+		return "", ErrPadToLengthExceeded
+	}
+
+	return strings.Join(password, ""), nil
+}
+
+func TestGenerateAdaptiveRejectAttempts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		rejections int
+		wantErr    error
+	}{
+		{name: "Redrawn until a password fits", rejections: padToLengthMaxAttempts - 1},
+		{name: "Every attempt rejected", rejections: padToLengthMaxAttempts, wantErr: ErrPadToLengthExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ps := &mockRejectPaddingService{rejections: tt.rejections}
+			svc, err := NewCustomPasswordGeneratorService(
+				&config.Settings{NumPasswords: 1},
+				&mockTransformerService{},
+				&mockSeparatorService{},
+				ps,
+				&mockWordListService{},
+			)
+			if err != nil {
+				t.Fatalf("NewCustomPasswordGeneratorService() error = %v", err)
+			}
+
+			_, err = svc.Generate()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Generate() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if want := min(tt.rejections+1, padToLengthMaxAttempts); ps.calls != want {
+				t.Errorf("Pad() called %d times, want %d", ps.calls, want)
+			}
+		})
+	}
+}
+
+func TestAdaptiveTruncationEntropyMinimum(t *testing.T) {
+	t.Parallel()
+
+	// Truncating to 4 characters leaves far fewer than 40 bits
+	cfg := config.DefaultSettings()
+	cfg.PaddingType = option.PaddingTypeAdaptive
+	cfg.PadToLength = 4
+	cfg.MinEntropyBits = 40
+
+	_, err := NewGeneratorServiceWithRNG(cfg, NewSeededRNGService([]byte("adaptive")))
+
+	var entropyErr *EntropyBelowMinimumError
+	if !errors.As(err, &entropyErr) {
+		t.Fatalf("NewGeneratorServiceWithRNG() error = %v, want an EntropyBelowMinimumError", err)
+	}
+
+	if !slices.Contains(entropyErr.Keys, option.ConfigKeyPadToLength) {
+		t.Errorf("EntropyBelowMinimumError.Keys = %v, want it to contain %q", entropyErr.Keys, option.ConfigKeyPadToLength)
+	}
+}
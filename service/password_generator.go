@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/eljamo/libpass/v8/config"
@@ -21,11 +22,12 @@ type PasswordGeneratorService interface {
 // combines various services like transformers, separators, padders, and word
 // list services to generate passwords based on provided configuration.
 type DefaultPasswordGeneratorService struct {
-	cfg            *config.Settings
-	transformerSvc TransformerService
-	separatorSvc   SeparatorService
-	paddingSvc     PaddingService
-	wordListSvc    WordListService
+	cfg             *config.Settings
+	transformerSvc  TransformerService
+	substitutionSvc SubstitutionService // Skipped when nil
+	separatorSvc    SeparatorService
+	paddingSvc      PaddingService
+	wordListSvc     WordListService
 }
 
 const (
@@ -45,8 +47,28 @@ func NewCustomPasswordGeneratorService(
 	paddingSvc PaddingService,
 	wordListSvc WordListService,
 ) (*DefaultPasswordGeneratorService, error) {
+	if err := validateNumPasswords(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validatePolicy(cfg); err != nil {
+		return nil, err
+	}
+
+	return &DefaultPasswordGeneratorService{
+		cfg:            cfg,
+		transformerSvc: transformerSvc,
+		separatorSvc:   separatorSvc,
+		paddingSvc:     paddingSvc,
+		wordListSvc:    wordListSvc,
+	}, nil
+}
+
+// Checks num_passwords is within the number of passwords a generator can
+// generate at once.
+func validateNumPasswords(cfg *config.Settings) error {
 	if cfg.NumPasswords < numPasswordMin || cfg.NumPasswords > numPasswordMax {
-		return nil, fmt.Errorf(
+		return fmt.Errorf(
 			"%s (%d) must be between %d and %d",
 			option.ConfigKeyNumPasswords,
 			cfg.NumPasswords,
@@ -55,32 +77,126 @@ func NewCustomPasswordGeneratorService(
 		)
 	}
 
-	return &DefaultPasswordGeneratorService{
-		cfg,
-		transformerSvc,
-		separatorSvc,
-		paddingSvc,
-		wordListSvc,
-	}, nil
+	return nil
+}
+
+// NewGeneratorService constructs the PasswordGeneratorService chosen by the
+// generator setting: a DefaultPasswordGeneratorService for WORDS, the default,
+// PRONOUNCEABLE and GRAMMATICAL, a CharacterPasswordGeneratorService for
+// CHARACTERS, or a PINPasswordGeneratorService for PIN. When pattern is set a
+// PatternPasswordGeneratorService lays out the words instead. It returns an
+// error if the generator is unknown or its configuration is invalid.
+func NewGeneratorService(cfg *config.Settings) (PasswordGeneratorService, error) {
+	return NewGeneratorServiceWithRNG(cfg, NewRNGService())
+}
+
+// NewGeneratorServiceWithRNG behaves like NewGeneratorService but initializes
+// the generator with the given random number generator service.
+func NewGeneratorServiceWithRNG(cfg *config.Settings, rngs RNGService) (PasswordGeneratorService, error) {
+	if cfg.Pattern != "" {
+		return newPatternGeneratorService(cfg, rngs)
+	}
+
+	switch cfg.Generator {
+	case "", option.GeneratorWords, option.GeneratorPronounceable, option.GeneratorGrammatical:
+		svc, err := NewPasswordGeneratorServiceWithRNG(cfg, rngs)
+		if err != nil {
+			return nil, err
+		}
+
+		return svc, nil
+	case option.GeneratorCharacters:
+		svc, err := NewCharacterPasswordGeneratorService(cfg, rngs)
+		if err != nil {
+			return nil, err
+		}
+
+		return svc, nil
+	case option.GeneratorPIN:
+		svc, err := NewPINPasswordGeneratorService(cfg, rngs)
+		if err != nil {
+			return nil, err
+		}
+
+		return svc, nil
+	}
+
+	return nil, fmt.Errorf("invalid %s value (%s)", option.ConfigKeyGenerator, cfg.Generator)
+}
+
+// Constructs the PatternPasswordGeneratorService for a pattern, which lays out
+// words drawn by the WORDS, PRONOUNCEABLE or GRAMMATICAL generators.
+func newPatternGeneratorService(cfg *config.Settings, rngs RNGService) (PasswordGeneratorService, error) {
+	switch cfg.Generator {
+	case "", option.GeneratorWords, option.GeneratorPronounceable, option.GeneratorGrammatical:
+	default:
+		return nil, fmt.Errorf("%s cannot be set when %s is %s", option.ConfigKeyPattern, option.ConfigKeyGenerator, cfg.Generator)
+	}
+
+	svc, err := NewPatternPasswordGeneratorService(cfg, rngs)
+	if err != nil {
+		return nil, err
+	}
+
+	return svc, nil
 }
 
 // NewPasswordGeneratorService constructs a DefaultPasswordGeneratorService with default
 // implementations for its dependent services (transformer, separator, padding, and word list services).
-// It initializes each service with the provided configuration and random number generator service.
+// It initializes each service with the provided configuration and random number generator service,
+// drawing words from several word lists when word_list_weights or word_list_slots is set,
+// generating pronounceable pseudo-words when generator is PRONOUNCEABLE, drawing words by
+// part of speech when generator is GRAMMATICAL, and replacing characters of the
+// transformed words when character_substitutions is set.
+// If min_entropy_bits is set and the seen entropy of the configuration is below it, an
+// EntropyBelowMinimumError is returned. An error is returned if generator is
+// CHARACTERS or PIN or pattern is set, which NewGeneratorService builds
+// instead.
 func NewPasswordGeneratorService(
 	cfg *config.Settings,
 ) (*DefaultPasswordGeneratorService, error) {
-	rngs := NewRNGService()
-	wls, err := NewWordListService(cfg, rngs)
+	return NewPasswordGeneratorServiceWithRNG(cfg, NewRNGService())
+}
+
+// NewPasswordGeneratorServiceWithRNG behaves like NewPasswordGeneratorService but
+// initializes the dependent services with the given random number generator service,
+// e.g. a SeededRNGService to make the generated passwords reproducible.
+func NewPasswordGeneratorServiceWithRNG(
+	cfg *config.Settings,
+	rngs RNGService,
+) (*DefaultPasswordGeneratorService, error) {
+	if err := validateWordGenerator(cfg); err != nil {
+		return nil, err
+	}
+
+	wls, err := newConfiguredWordListService(cfg, rngs)
 	if err != nil {
 		return nil, err
 	}
 
+	es, err := NewEntropyServiceFromWordList(cfg, wls)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := es.checkMinimum(); err != nil {
+		return nil, err
+	}
+
+	if err := es.checkPadToLength(); err != nil {
+		return nil, err
+	}
+
 	ts, err := NewTransformerService(cfg, rngs)
 	if err != nil {
 		return nil, err
 	}
 
+	subs, err := NewSubstitutionService(cfg, rngs)
+	if err != nil {
+		return nil, err
+	}
+
 	ss, err := NewSeparatorService(cfg, rngs)
 	if err != nil {
 		return nil, err
@@ -91,42 +207,92 @@ func NewPasswordGeneratorService(
 		return nil, err
 	}
 
-	return NewCustomPasswordGeneratorService(cfg, ts, ss, ps, wls)
+	svc, err := NewCustomPasswordGeneratorService(cfg, ts, ss, ps, wls)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateWordPolicy(cfg); err != nil {
+		return nil, err
+	}
+
+	if len(cfg.CharacterSubstitutions) > 0 {
+		svc.substitutionSvc = subs
+	}
+
+	return svc, nil
+}
+
+// Checks the generator setting chooses passwords built from words in the
+// fixed layout a DefaultPasswordGeneratorService generates, rather than
+// ignoring a generator or pattern it cannot build. NewGeneratorService builds
+// the others.
+func validateWordGenerator(cfg *config.Settings) error {
+	if cfg.Pattern != "" {
+		return fmt.Errorf("%s cannot be set for a fixed layout, use NewGeneratorService", option.ConfigKeyPattern)
+	}
+
+	switch cfg.Generator {
+	case "", option.GeneratorWords, option.GeneratorPronounceable, option.GeneratorGrammatical:
+		return nil
+	case option.GeneratorCharacters, option.GeneratorPIN:
+		return fmt.Errorf("%s %s does not generate words, use NewGeneratorService", option.ConfigKeyGenerator, cfg.Generator)
+	}
+
+	return fmt.Errorf("invalid %s value (%s)", option.ConfigKeyGenerator, cfg.Generator)
 }
 
 // Generate creates a list of passwords using the services provided to the
 // DefaultPasswordGeneratorService instance and returns the list of generated
-// passwords or the first error if one or more is encountered.
+// passwords or the first error if one or more is encountered. When policy is
+// set each password is generated again while it breaks the policy, and a
+// PolicyUnsatisfiableError is returned if it cannot be followed.
 func (s *DefaultPasswordGeneratorService) Generate() ([]string, error) {
-	pws := make([]string, s.cfg.NumPasswords)
+	return generatePasswords(s.cfg, s.generateOne)
+}
 
-	for i := 0; i < s.cfg.NumPasswords; i++ {
-		// Get a list of words from the wordList service
-		sl, err := s.wordListSvc.GetWords()
-		if err != nil {
-			return nil, err
+// Generates a single password, generating it again while it is longer than
+// pad_to_length and padding_overflow is REJECT. It returns an error wrapping
+// ErrPadToLengthExceeded if every one of padToLengthMaxAttempts attempts is.
+func (s *DefaultPasswordGeneratorService) generateOne() (string, error) {
+	for range padToLengthMaxAttempts {
+		pw, err := s.generateCandidate()
+		if !errors.Is(err, ErrPadToLengthExceeded) {
+			return pw, err
 		}
+	}
 
-		// Transform the casing of words or letters using the transformer service
-		slt, err := s.transformerSvc.Transform(sl)
-		if err != nil {
-			return nil, err
-		}
+	return "", fmt.Errorf("%w after %d attempts", ErrPadToLengthExceeded, padToLengthMaxAttempts)
+}
 
-		// Separate the transformed list using the separator service using special characters
-		sls, err := s.separatorSvc.Separate(slt)
-		if err != nil {
-			return nil, err
-		}
+// Generates a single password from the words of the word list service.
+func (s *DefaultPasswordGeneratorService) generateCandidate() (string, error) {
+	// Get a list of words from the wordList service
+	sl, err := s.wordListSvc.GetWords()
+	if err != nil {
+		return "", err
+	}
 
-		// Pad the password with digits and special characters using the padding service
-		pw, err := s.paddingSvc.Pad(sls)
+	// Transform the casing of words or letters using the transformer service
+	slt, err := s.transformerSvc.Transform(sl)
+	if err != nil {
+		return "", err
+	}
+
+	// Replace characters of the transformed words using the substitution service
+	if s.substitutionSvc != nil {
+		slt, err = s.substitutionSvc.Substitute(slt)
 		if err != nil {
-			return nil, err
+			return "", err
 		}
+	}
 
-		pws[i] = pw
+	// Separate the transformed list using the separator service using special characters
+	sls, err := s.separatorSvc.Separate(slt)
+	if err != nil {
+		return "", err
 	}
 
-	return pws, nil
+	// Pad the password with digits and special characters using the padding service
+	return s.paddingSvc.Pad(sls)
 }
@@ -2,10 +2,12 @@ package service
 
 import (
 	"errors"
+	"slices"
 	"strings"
 	"testing"
 
 	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
 )
 
 type mockTransformerService struct{}
@@ -111,11 +113,11 @@ func TestPasswordGenerate(t *testing.T) {
 	) *DefaultPasswordGeneratorService {
 		cfg := &config.Settings{NumPasswords: 2, NumWords: 2}
 		return &DefaultPasswordGeneratorService{
-			cfg,
-			transformer,
-			separator,
-			padding,
-			wordList,
+			cfg:            cfg,
+			transformerSvc: transformer,
+			separatorSvc:   separator,
+			paddingSvc:     padding,
+			wordListSvc:    wordList,
 		}
 	}
 
@@ -146,3 +148,156 @@ func TestPasswordGenerate(t *testing.T) {
 		})
 	}
 }
+
+func TestNewPasswordGeneratorServiceMinEntropy(t *testing.T) {
+	t.Parallel()
+
+	// EN words of exactly 5 letters with a fixed separator and INVERT casing
+	// give well under 40 bits of seen entropy
+	newCfg := func(minEntropyBits int) *config.Settings {
+		cfg := config.DefaultSettings()
+		cfg.NumWords = 2
+		cfg.WordLengthMin = 5
+		cfg.WordLengthMax = 5
+		cfg.CaseTransform = option.CaseTransformInvert
+		cfg.SeparatorCharacter = "-"
+		cfg.PaddingDigitsBefore = 1
+		cfg.PaddingDigitsAfter = 0
+		cfg.MinEntropyBits = minEntropyBits
+
+		return cfg
+	}
+
+	tests := []struct {
+		name     string
+		cfg      *config.Settings
+		wantErr  bool
+		wantKeys []string
+		noKeys   []string
+	}{
+		{
+			name:    "Disabled",
+			cfg:     newCfg(0),
+			wantErr: false,
+		},
+		{
+			name:    "Entropy above minimum",
+			cfg:     newCfg(20),
+			wantErr: false,
+		},
+		{
+			name:    "Entropy below minimum",
+			cfg:     newCfg(40),
+			wantErr: true,
+			wantKeys: []string{
+				option.ConfigKeyNumWords,
+				option.ConfigKeyWordLengthMax,
+				option.ConfigKeyCaseTransform,
+				option.ConfigKeySeparatorCharacter,
+			},
+		},
+		{
+			name: "No longer words to allow",
+			cfg: func() *config.Settings {
+				cfg := newCfg(200)
+				cfg.WordLengthMax = 100
+
+				return cfg
+			}(),
+			wantErr:  true,
+			wantKeys: []string{option.ConfigKeyNumWords},
+			noKeys:   []string{option.ConfigKeyWordLengthMax},
+		},
+		{
+			name:    "Negative minimum",
+			cfg:     newCfg(-1),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewPasswordGeneratorService(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewPasswordGeneratorService() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantKeys == nil {
+				return
+			}
+
+			if !errors.Is(err, ErrEntropyBelowMinimum) {
+				t.Errorf("NewPasswordGeneratorService() error = %v, want ErrEntropyBelowMinimum", err)
+			}
+
+			var entropyErr *EntropyBelowMinimumError
+			if !errors.As(err, &entropyErr) {
+				t.Fatalf("NewPasswordGeneratorService() error = %T, want *EntropyBelowMinimumError", err)
+			}
+
+			if entropyErr.Seen >= float64(entropyErr.Min) {
+				t.Errorf("EntropyBelowMinimumError.Seen = %f, want below %d", entropyErr.Seen, entropyErr.Min)
+			}
+
+			for _, key := range tt.wantKeys {
+				if !slices.Contains(entropyErr.Keys, key) {
+					t.Errorf("EntropyBelowMinimumError.Keys = %v, want it to contain %q", entropyErr.Keys, key)
+				}
+			}
+
+			for _, key := range tt.noKeys {
+				if slices.Contains(entropyErr.Keys, key) {
+					t.Errorf("EntropyBelowMinimumError.Keys = %v, want it not to contain %q", entropyErr.Keys, key)
+				}
+			}
+		})
+	}
+}
+
+func TestNewPasswordGeneratorServiceOtherGenerators(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		cfg  func(cfg *config.Settings)
+	}{
+		{
+			name: "Characters",
+			cfg: func(cfg *config.Settings) {
+				cfg.Generator = option.GeneratorCharacters
+				cfg.Length = 16
+				cfg.CharacterClasses = map[string]int{option.CharacterClassLower: 0}
+			},
+		},
+		{
+			name: "PIN",
+			cfg: func(cfg *config.Settings) {
+				cfg.Generator = option.GeneratorPIN
+				cfg.Length = 6
+			},
+		},
+		{
+			name: "Pattern",
+			cfg:  func(cfg *config.Settings) { cfg.Pattern = "dd-W-s-w-dd" },
+		},
+		{
+			name: "Unknown generator",
+			cfg:  func(cfg *config.Settings) { cfg.Generator = "EMOJI" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := config.DefaultSettings()
+			tt.cfg(cfg)
+
+			if _, err := NewPasswordGeneratorService(cfg); err == nil {
+				t.Error("NewPasswordGeneratorService() error = nil, want an error")
+			}
+		})
+	}
+}
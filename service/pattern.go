@@ -0,0 +1,313 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+	"github.com/eljamo/libpass/v8/internal/validator"
+)
+
+// Characters of a pattern
+const (
+	patternWordCapitalised rune = 'W'
+	patternWordLower       rune = 'w'
+	patternDigit           rune = 'd'
+	patternSymbol          rune = 's'
+	patternEscape          rune = '\\'
+)
+
+// An element of a pattern: a word, digit or symbol drawn at random, or a
+// literal character.
+type patternToken struct {
+	kind    rune   // One of the pattern characters, or 0 for a literal
+	literal string // The literal character when kind is 0
+}
+
+// Parses a pattern into its tokens. It returns an error if the pattern ends
+// with an unfinished escape.
+func parsePattern(pattern string) ([]patternToken, error) {
+	var tokens []patternToken
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			tokens = append(tokens, patternToken{literal: string(r)})
+			escaped = false
+		case r == patternEscape:
+			escaped = true
+		case r == patternWordCapitalised, r == patternWordLower, r == patternDigit, r == patternSymbol:
+			tokens = append(tokens, patternToken{kind: r})
+		default:
+			tokens = append(tokens, patternToken{literal: string(r)})
+		}
+	}
+
+	if escaped {
+		return nil, fmt.Errorf("%s (%s) cannot end with an unfinished escape", option.ConfigKeyPattern, pattern)
+	}
+
+	return tokens, nil
+}
+
+// PatternPasswordGeneratorService implements the PasswordGeneratorService
+// interface, laying out each password as the pattern setting describes instead
+// of the fixed order of padding digits, words, separators and padding
+// symbols. In a pattern W is a capitalised word, w a lower case word, d a
+// digit, s a symbol from symbol_alphabet, and any other character, or one
+// escaped with a backslash, is copied verbatim, e.g. dd-W-s-w-W-dd. The words
+// are drawn as the generator and word list settings describe, and
+// case_transform is replaced by the case of each word in the pattern. It also
+// implements the EntropyService interface.
+type PatternPasswordGeneratorService struct {
+	cfg         *config.Settings
+	rngSvc      RNGService
+	tokens      []patternToken
+	wordListSvc reportingWordListService
+	symbols     []string // The symbols s draws from
+	counts      map[rune]int
+}
+
+// Creates a new instance of PatternPasswordGeneratorService from the pattern
+// setting. It returns an error if the pattern or the configuration of the
+// words it draws is invalid, and an EntropyBelowMinimumError if
+// min_entropy_bits is set and the seen entropy is below it.
+func NewPatternPasswordGeneratorService(cfg *config.Settings, rngSvc RNGService) (*PatternPasswordGeneratorService, error) {
+	if err := validateNumPasswords(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validatePolicy(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validateNoSubstitutions(cfg, option.ConfigKeyPattern, cfg.Pattern); err != nil {
+		return nil, err
+	}
+
+	tokens, err := parsePattern(cfg.Pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	svc := &PatternPasswordGeneratorService{
+		cfg:     cfg,
+		rngSvc:  rngSvc,
+		tokens:  tokens,
+		symbols: effectiveAlphabet(cfg, cfg.SymbolAlphabet),
+		counts:  make(map[rune]int),
+	}
+	for _, t := range tokens {
+		svc.counts[t.kind]++
+	}
+
+	if err := svc.validatePolicyStart(); err != nil {
+		return nil, err
+	}
+
+	numWords := svc.counts[patternWordCapitalised] + svc.counts[patternWordLower]
+	if numWords < numWordMin {
+		return nil, fmt.Errorf("%s (%s) must contain at least %d words", option.ConfigKeyPattern, cfg.Pattern, numWordMin)
+	}
+
+	if svc.counts[patternSymbol] > 0 {
+		if len(svc.symbols) == 0 {
+			return nil, fmt.Errorf("%s cannot be empty when %s contains symbols", option.ConfigKeySymbolAlphabet, option.ConfigKeyPattern)
+		}
+
+		if validator.HasElementWithLengthGreaterThanOne(svc.symbols) {
+			return nil, fmt.Errorf("%s cannot contain elements with a length greater than 1", option.ConfigKeySymbolAlphabet)
+		}
+	}
+
+	// The words are drawn in the case of the pattern, which decides which
+	// words are ambiguous when avoid_ambiguous is set
+	wordCfg := *cfg
+	wordCfg.NumWords = numWords
+	wordCfg.CaseTransform = svc.wordCaseTransform()
+
+	svc.wordListSvc, err = newConfiguredWordListService(&wordCfg, rngSvc)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.MinEntropyBits > 0 {
+		if seen := svc.seen(); seen < float64(cfg.MinEntropyBits) {
+			keys := []string{option.ConfigKeyPattern}
+			if r, ok := svc.wordListSvc.(longerWordsReporter); ok && r.hasLongerWords() {
+				keys = append(keys, option.ConfigKeyWordLengthMax)
+			}
+
+			return nil, &EntropyBelowMinimumError{
+				Seen: seen,
+				Min:  cfg.MinEntropyBits,
+				Keys: keys,
+			}
+		}
+	}
+
+	return svc, nil
+}
+
+// Checks must_start_with_letter in the policy setting, if it is set, can be
+// followed by the first token of the pattern: a word, a letter, or a symbol
+// when symbol_alphabet has letters.
+func (s *PatternPasswordGeneratorService) validatePolicyStart() error {
+	if s.cfg.Policy == nil || !s.cfg.Policy.MustStartWithLetter || len(s.tokens) == 0 {
+		return nil
+	}
+
+	var chars []string
+	switch first := s.tokens[0]; first.kind {
+	case patternWordCapitalised, patternWordLower:
+		return nil
+	case patternSymbol:
+		chars = s.symbols
+	case 0:
+		chars = []string{first.literal}
+	}
+
+	if containsRune(chars, unicode.IsLetter) {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"%s %s cannot be followed, %s (%s) doesn't start with a letter",
+		option.ConfigKeyPolicy,
+		option.PolicyKeyMustStartWithLetter,
+		option.ConfigKeyPattern,
+		s.cfg.Pattern,
+	)
+}
+
+// Returns the case transformation giving the forms the words of the pattern
+// take: capitalised, lower case, or either.
+func (s *PatternPasswordGeneratorService) wordCaseTransform() string {
+	switch {
+	case s.counts[patternWordLower] == 0:
+		return option.CaseTransformCapitalise
+	case s.counts[patternWordCapitalised] == 0:
+		return option.CaseTransformLower
+	}
+
+	return option.CaseTransformSentence
+}
+
+// Generate creates a list of passwords laid out as the pattern describes,
+// following the policy if one is set, and returns the list or the first error
+// encountered.
+func (s *PatternPasswordGeneratorService) Generate() ([]string, error) {
+	return generatePasswords(s.cfg, s.generateOne)
+}
+
+// Generates a single password, filling in each token of the pattern in turn.
+func (s *PatternPasswordGeneratorService) generateOne() (string, error) {
+	words, err := s.wordListSvc.GetWords()
+	if err != nil {
+		return "", err
+	}
+
+	// The methods used don't depend on the transformer's configuration
+	t := &DefaultTransformerService{}
+
+	var sb strings.Builder
+	for _, tok := range s.tokens {
+		switch tok.kind {
+		case patternWordCapitalised:
+			sb.WriteString(t.capitalise([]string{strings.ToLower(words[0])})[0])
+			words = words[1:]
+		case patternWordLower:
+			sb.WriteString(strings.ToLower(words[0]))
+			words = words[1:]
+		case patternDigit:
+			d, err := generateDigit(s.cfg, s.rngSvc)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(strconv.Itoa(d))
+		case patternSymbol:
+			n, err := s.rngSvc.GenerateWithMax(len(s.symbols))
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(s.symbols[n])
+		default:
+			sb.WriteString(tok.literal)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// Calculate returns the blind and seen entropy of the passwords produced by
+// the service's configuration.
+func (s *PatternPasswordGeneratorService) Calculate() (*Entropy, error) {
+	minLen, maxLen := s.lengthRange()
+	poolBits := math.Log2(float64(s.characterPoolSize()))
+
+	return &Entropy{
+		BlindMin: float64(minLen) * poolBits,
+		BlindMax: float64(maxLen) * poolBits,
+		Seen:     s.seen(),
+	}, nil
+}
+
+// Returns the entropy for an attacker who knows the pattern and the word
+// list: that of the words, digits and symbols. Literal characters add none.
+func (s *PatternPasswordGeneratorService) seen() float64 {
+	bits := s.wordListSvc.WordEntropy()
+	bits += float64(s.counts[patternDigit]) * math.Log2(float64(numPaddingDigits(s.cfg)))
+	if n := s.counts[patternSymbol]; n > 0 {
+		bits += float64(n) * math.Log2(float64(len(s.symbols)))
+	}
+
+	return bits
+}
+
+// Returns the minimum and maximum length of a password in runes.
+func (s *PatternPasswordGeneratorService) lengthRange() (int, int) {
+	wordMin, wordMax := reportedWordLengthRange(s.wordListSvc)
+	numWords := s.counts[patternWordCapitalised] + s.counts[patternWordLower]
+	fixed := s.counts[patternDigit] + s.counts[patternSymbol] + s.counts[0]
+
+	return numWords*wordMin + fixed, numWords*wordMax + fixed
+}
+
+// Returns the size of the pool of characters an attacker would have to search
+// to brute force a password, based on the character classes it can contain.
+func (s *PatternPasswordGeneratorService) characterPoolSize() int {
+	var lower, digit, symbol bool
+	upper := s.counts[patternWordCapitalised] > 0
+	classify := func(text string) {
+		for _, r := range text {
+			switch {
+			case unicode.IsUpper(r):
+				upper = true
+			case unicode.IsLetter(r):
+				lower = true
+			case unicode.IsDigit(r):
+				digit = true
+			default:
+				symbol = true
+			}
+		}
+	}
+
+	// Every word has a lower case form
+	for _, w := range reportedWords(s.wordListSvc) {
+		classify(strings.ToLower(w))
+	}
+
+	for _, tok := range s.tokens {
+		classify(tok.literal)
+	}
+
+	digit = digit || s.counts[patternDigit] > 0
+	symbol = symbol || s.counts[patternSymbol] > 0
+
+	return poolSize(lower, upper, digit, symbol)
+}
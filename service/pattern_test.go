@@ -0,0 +1,204 @@
+package service
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+func TestParsePattern(t *testing.T) {
+	t.Parallel()
+
+	got, err := parsePattern(`dW-\ws!`)
+	if err != nil {
+		t.Fatalf("parsePattern() error = %v", err)
+	}
+
+	want := []patternToken{
+		{kind: patternDigit},
+		{kind: patternWordCapitalised},
+		{literal: "-"},
+		{literal: "w"},
+		{kind: patternSymbol},
+		{literal: "!"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("parsePattern() = %v, want %v", got, want)
+	}
+
+	if _, err := parsePattern(`W-w\`); err == nil {
+		t.Error("parsePattern() error = nil, want an error for the unfinished escape")
+	}
+}
+
+func newPatternTestSettings(t *testing.T, pattern string) *config.Settings {
+	t.Helper()
+
+	cfg := newCompositeTestSettings(t)
+	cfg.NumPasswords = 1
+	cfg.Pattern = pattern
+	cfg.WordListSlots = []string{compositeTestWordListA, compositeTestWordListB}
+	cfg.SymbolAlphabet = []string{"!", "@", "$", "%"}
+
+	return cfg
+}
+
+func TestPatternPasswordGeneratorServiceGenerate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		pattern        string
+		avoidAmbiguous bool
+		want           string
+	}{
+		{
+			name:    "Digits between words",
+			pattern: "W1w",
+			want:    "Bravo1echo",
+		},
+		{
+			name:    "Every token",
+			pattern: `dd-W-s-w-!\d`,
+			want:    "11-Bravo-@-echo-!d",
+		},
+		{
+			// delta is left out as it contains an l
+			name:           "Avoid ambiguous",
+			pattern:        "wdW",
+			avoidAmbiguous: true,
+			want:           "foxtrot3Foxtrot",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := newPatternTestSettings(t, tt.pattern)
+			if tt.avoidAmbiguous {
+				cfg.AvoidAmbiguous = true
+				cfg.WordListSlots = []string{compositeTestWordListB, compositeTestWordListB}
+			}
+
+			svc, err := NewPatternPasswordGeneratorService(cfg, &mockRNGService{})
+			if err != nil {
+				t.Fatalf("NewPatternPasswordGeneratorService() error = %v", err)
+			}
+
+			got, err := svc.Generate()
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			if want := []string{tt.want}; !slices.Equal(got, want) {
+				t.Errorf("Generate() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestNewPatternPasswordGeneratorServiceErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		modify func(cfg *config.Settings)
+	}{
+		{
+			name:   "A single word",
+			modify: func(cfg *config.Settings) { cfg.Pattern = "W-dd" },
+		},
+		{
+			name:   "Unfinished escape",
+			modify: func(cfg *config.Settings) { cfg.Pattern = `W-w\` },
+		},
+		{
+			name:   "Symbols without an alphabet",
+			modify: func(cfg *config.Settings) { cfg.SymbolAlphabet = nil },
+		},
+		{
+			name:   "Multi-character symbols",
+			modify: func(cfg *config.Settings) { cfg.SymbolAlphabet = []string{"!!"} },
+		},
+		{
+			name:   "More words than slots",
+			modify: func(cfg *config.Settings) { cfg.Pattern = "W-w-W-s" },
+		},
+		{
+			name:   "Below the minimum entropy",
+			modify: func(cfg *config.Settings) { cfg.MinEntropyBits = 20 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := newPatternTestSettings(t, "W-w-s")
+			tt.modify(cfg)
+
+			if _, err := NewPatternPasswordGeneratorService(cfg, &mockRNGService{}); err == nil {
+				t.Errorf("%s: NewPatternPasswordGeneratorService() error = nil, want an error", tt.name)
+			}
+		})
+	}
+}
+
+func TestPatternPasswordGeneratorServiceCalculate(t *testing.T) {
+	t.Parallel()
+
+	cfg := newPatternTestSettings(t, "dd-W-s-w")
+
+	svc, err := NewPatternPasswordGeneratorService(cfg, &mockRNGService{})
+	if err != nil {
+		t.Fatalf("NewPatternPasswordGeneratorService() error = %v", err)
+	}
+
+	got, err := svc.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+
+	// Words of 4 to 7 letters from each list, 2 digits, a symbol and 3
+	// dashes, from lower and upper case letters, digits and symbols
+	pool := math.Log2(26 + 26 + 10 + 33)
+	want := Entropy{
+		BlindMin: (2*4 + 6) * pool,
+		BlindMax: (2*7 + 6) * pool,
+		Seen:     math.Log2(4) + math.Log2(3) + 2*math.Log2(10) + math.Log2(4),
+	}
+	if math.Abs(got.BlindMin-want.BlindMin) > entropyTolerance ||
+		math.Abs(got.BlindMax-want.BlindMax) > entropyTolerance ||
+		math.Abs(got.Seen-want.Seen) > entropyTolerance {
+		t.Errorf("Calculate() = %+v, want %+v", *got, want)
+	}
+}
+
+func TestNewGeneratorServicePattern(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.DefaultSettings()
+	cfg.Pattern = "dd-W-s-w-W-dd"
+
+	svc, err := NewGeneratorService(cfg)
+	if err != nil {
+		t.Fatalf("NewGeneratorService() error = %v", err)
+	}
+
+	if _, ok := svc.(*PatternPasswordGeneratorService); !ok {
+		t.Errorf("NewGeneratorService() = %T, want *PatternPasswordGeneratorService", svc)
+	}
+
+	if _, err := svc.Generate(); err != nil {
+		t.Errorf("Generate() error = %v", err)
+	}
+
+	cfg.Generator = option.GeneratorPIN
+	if _, err := NewGeneratorService(cfg); err == nil {
+		t.Error("NewGeneratorService() error = nil, want an error for a pattern with the PIN generator")
+	}
+}
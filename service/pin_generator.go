@@ -0,0 +1,274 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/eljamo/libpass/v8/asset"
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+const (
+	pinLengthMin int = 4
+	pinLengthMax int = 12
+	// The number of times a PIN is drawn again when it is weak, before giving
+	// up
+	pinMaxAttempts int = 1000
+	// The range of years a PIN is checked for as a date
+	pinYearMin int = 1900
+	pinYearMax int = 2099
+	// The longest block of digits a PIN is checked for repeating, e.g. 1212
+	pinRepeatedBlockMax int = 2
+)
+
+var ErrWeakPIN = errors.New("failed to draw a PIN which isn't weak")
+
+// The straight lines of keys on a phone keypad, with 0 below 8
+var keypadLines = []string{"123", "456", "789", "147", "258", "369", "2580", "159", "357"}
+
+// The number of days in each month, allowing 29 February
+var daysInMonth = [12]int{31, 29, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+
+// PINPasswordGeneratorService implements the PasswordGeneratorService
+// interface, generating numeric PINs of length digits for door codes,
+// voicemail and the like. PINs following a known weak pattern are drawn
+// again: a single digit or a block of two digits repeated, an ascending or
+// descending run, straight lines on a phone keypad, dates, the embedded list
+// of common PINs, and any numbers in the blocklist. Every other PIN is
+// equally likely. It also implements the EntropyService interface.
+type PINPasswordGeneratorService struct {
+	cfg    *config.Settings
+	rngSvc RNGService
+	weak   map[string]bool // The weak PINs of length digits
+	bits   float64         // The seen entropy of a PIN
+}
+
+// Creates a new instance of PINPasswordGeneratorService generating PINs of
+// length digits. It returns an error if the configuration is invalid, and an
+// EntropyBelowMinimumError if min_entropy_bits is set and the seen entropy is
+// below it.
+func NewPINPasswordGeneratorService(cfg *config.Settings, rngSvc RNGService) (*PINPasswordGeneratorService, error) {
+	if err := validateNumPasswords(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validatePolicy(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validateNoSubstitutions(cfg, option.ConfigKeyGenerator, option.GeneratorPIN); err != nil {
+		return nil, err
+	}
+
+	pinSource := fmt.Sprintf("%s %s", option.ConfigKeyGenerator, option.GeneratorPIN)
+	if err := validatePolicyCharacters(cfg, charRange('0', '9'), pinSource); err != nil {
+		return nil, err
+	}
+
+	if cfg.Length < pinLengthMin || cfg.Length > pinLengthMax {
+		return nil, fmt.Errorf(
+			"%s (%d) must be between %d and %d when %s is %s",
+			option.ConfigKeyLength,
+			cfg.Length,
+			pinLengthMin,
+			pinLengthMax,
+			option.ConfigKeyGenerator,
+			option.GeneratorPIN,
+		)
+	}
+
+	weak, err := weakPINs(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	svc := &PINPasswordGeneratorService{
+		cfg:    cfg,
+		rngSvc: rngSvc,
+		weak:   weak,
+		bits:   math.Log2(math.Pow10(cfg.Length) - float64(len(weak))),
+	}
+
+	if cfg.MinEntropyBits > 0 && svc.bits < float64(cfg.MinEntropyBits) {
+		return nil, &EntropyBelowMinimumError{
+			Seen: svc.bits,
+			Min:  cfg.MinEntropyBits,
+			Keys: []string{option.ConfigKeyLength},
+		}
+	}
+
+	return svc, nil
+}
+
+// Returns the set of weak PINs of length digits. It returns an error if the
+// common PINs or the blocklist cannot be read.
+func weakPINs(cfg *config.Settings) (map[string]bool, error) {
+	length := cfg.Length
+	weak := make(map[string]bool)
+	add := func(pin string) {
+		if len(pin) == length {
+			weak[pin] = true
+		}
+	}
+
+	addRepeatedPINs(length, add)
+	addRunPINs(length, add)
+	addKeypadPINs("", length, add)
+	addDatePINs(length, add)
+
+	common, err := asset.GetCommonPINs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the common PINs: %w", err)
+	}
+
+	blocked, err := blocklistWords(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pin := range append(common, blocked...) {
+		if pin = strings.TrimSpace(pin); strings.Trim(pin, "0123456789") == "" {
+			add(pin)
+		}
+	}
+
+	return weak, nil
+}
+
+// Adds the PINs which repeat a single digit or a block of digits, e.g. 1111
+// and 1212.
+func addRepeatedPINs(length int, add func(string)) {
+	for size := 1; size <= pinRepeatedBlockMax; size++ {
+		if length%size != 0 {
+			continue
+		}
+
+		for block := range int(math.Pow10(size)) {
+			add(strings.Repeat(fmt.Sprintf("%0*d", size, block), length/size))
+		}
+	}
+}
+
+// Adds the PINs which ascend or descend one digit at a time, wrapping from 9
+// to 0, e.g. 1234, 7890 and 4321.
+func addRunPINs(length int, add func(string)) {
+	for start := range maxDigit {
+		for _, step := range []int{1, maxDigit - 1} {
+			var sb strings.Builder
+			for i := range length {
+				sb.WriteString(strconv.Itoa((start + i*step) % maxDigit))
+			}
+			add(sb.String())
+		}
+	}
+}
+
+// Adds the PINs made of straight lines of keys on a phone keypad, each read
+// in either direction, e.g. 2580 and 147258.
+func addKeypadPINs(prefix string, length int, add func(string)) {
+	if len(prefix) == length {
+		add(prefix)
+		return
+	}
+
+	for _, line := range keypadLines {
+		for _, l := range []string{line, reverseString(line)} {
+			if len(prefix)+len(l) <= length {
+				addKeypadPINs(prefix+l, length, add)
+			}
+		}
+	}
+}
+
+// Adds the PINs of length digits which are dates: DDMM, MMDD and YYYY;
+// DDMMYY, MMDDYY and YYMMDD; or DDMMYYYY, MMDDYYYY and YYYYMMDD.
+func addDatePINs(length int, add func(string)) {
+	const (
+		shortDateLength = 4
+		dateLength      = 6
+		longDateLength  = 8
+	)
+
+	for month, days := range daysInMonth {
+		for day := 1; day <= days; day++ {
+			dd, mm := fmt.Sprintf("%02d", day), fmt.Sprintf("%02d", month+1)
+			if length == shortDateLength {
+				add(dd + mm)
+				add(mm + dd)
+				continue
+			}
+
+			for year := pinYearMin; year <= pinYearMax; year++ {
+				yyyy := strconv.Itoa(year)
+				switch yy := yyyy[2:]; length {
+				case dateLength:
+					add(dd + mm + yy)
+					add(mm + dd + yy)
+					add(yy + mm + dd)
+				case longDateLength:
+					add(dd + mm + yyyy)
+					add(mm + dd + yyyy)
+					add(yyyy + mm + dd)
+				}
+			}
+		}
+	}
+
+	for year := pinYearMin; year <= pinYearMax; year++ {
+		add(strconv.Itoa(year))
+	}
+}
+
+// Returns the string with its runes in reverse order.
+func reverseString(s string) string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+
+	return string(r)
+}
+
+// Generate creates a list of PINs, following the policy if one is set, and
+// returns the list or the first error encountered.
+func (s *PINPasswordGeneratorService) Generate() ([]string, error) {
+	return generatePasswords(s.cfg, s.generateOne)
+}
+
+// Draws length random digits until they don't form a weak PIN. It returns
+// ErrWeakPIN if every attempt does.
+func (s *PINPasswordGeneratorService) generateOne() (string, error) {
+	for range pinMaxAttempts {
+		var sb strings.Builder
+		for range s.cfg.Length {
+			d, err := s.rngSvc.GenerateDigit()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(strconv.Itoa(d))
+		}
+
+		if pin := sb.String(); !s.weak[pin] {
+			return pin, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w after %d attempts", ErrWeakPIN, pinMaxAttempts)
+}
+
+// Calculate returns the blind and seen entropy of the PINs produced by the
+// service's configuration. The seen entropy is that of a PIN drawn from the
+// PINs which aren't weak.
+func (s *PINPasswordGeneratorService) Calculate() (*Entropy, error) {
+	blind := float64(s.cfg.Length) * math.Log2(float64(poolSizeDigit))
+
+	return &Entropy{
+		BlindMin: blind,
+		BlindMax: blind,
+		Seen:     s.bits,
+	}, nil
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+func newPINTestSettings(length int) *config.Settings {
+	return &config.Settings{Generator: option.GeneratorPIN, NumPasswords: 3, Length: length}
+}
+
+func TestWeakPINs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		pin  string
+		want bool
+	}{
+		{name: "Repeated digit", pin: "7777", want: true},
+		{name: "Repeated block", pin: "474747", want: true},
+		{name: "Ascending run", pin: "3456", want: true},
+		{name: "Descending run wrapping from 0 to 9", pin: "10987", want: true},
+		{name: "Keypad column", pin: "2580", want: true},
+		{name: "Keypad lines", pin: "147963", want: true},
+		{name: "Day and month", pin: "2512", want: true},
+		{name: "Month and day", pin: "1225", want: true},
+		{name: "Year", pin: "1984", want: true},
+		{name: "Long date", pin: "19840704", want: true},
+		{name: "Common PIN", pin: "6969", want: true},
+		{name: "Blocklisted number", pin: "4826", want: true},
+		{name: "Random PIN", pin: "7392", want: false},
+		{name: "Not a whole keypad line", pin: "1357", want: false},
+		{name: "Invalid date", pin: "3102", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := newPINTestSettings(len(tt.pin))
+			cfg.Blocklist = []string{"4826", "words"}
+
+			weak, err := weakPINs(cfg)
+			if err != nil {
+				t.Fatalf("weakPINs() error = %v", err)
+			}
+
+			if got := weak[tt.pin]; got != tt.want {
+				t.Errorf("weakPINs()[%q] = %v, want %v", tt.pin, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPINPasswordGeneratorServiceGenerate(t *testing.T) {
+	t.Parallel()
+
+	for _, length := range []int{pinLengthMin, 6, pinLengthMax} {
+		cfg := newPINTestSettings(length)
+
+		svc, err := NewPINPasswordGeneratorService(cfg, NewSeededRNGService([]byte("pin")))
+		if err != nil {
+			t.Fatalf("NewPINPasswordGeneratorService() error = %v", err)
+		}
+
+		for range presetContractIterations {
+			pins, err := svc.Generate()
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			for _, pin := range pins {
+				if len(pin) != length || strings.Trim(pin, "0123456789") != "" {
+					t.Fatalf("Generate() = %q, want %d digits", pin, length)
+				}
+
+				if svc.weak[pin] {
+					t.Fatalf("Generate() = %q, which is weak", pin)
+				}
+			}
+		}
+	}
+
+	// The mock RNG only draws 1s
+	svc, err := NewPINPasswordGeneratorService(newPINTestSettings(pinLengthMin), &mockRNGService{})
+	if err != nil {
+		t.Fatalf("NewPINPasswordGeneratorService() error = %v", err)
+	}
+
+	if _, err := svc.Generate(); !errors.Is(err, ErrWeakPIN) {
+		t.Errorf("Generate() error = %v, want %v", err, ErrWeakPIN)
+	}
+
+	errSvc, err := NewPINPasswordGeneratorService(newPINTestSettings(pinLengthMin), &mockErrRNGService{})
+	if err != nil {
+		t.Fatalf("NewPINPasswordGeneratorService() error = %v", err)
+	}
+
+	if _, err := errSvc.Generate(); err == nil {
+		t.Error("Generate() error = nil, want the RNG error")
+	}
+}
+
+func TestNewPINPasswordGeneratorServiceErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		modify func(cfg *config.Settings)
+	}{
+		{
+			name:   "Too short",
+			modify: func(cfg *config.Settings) { cfg.Length = pinLengthMin - 1 },
+		},
+		{
+			name:   "Too long",
+			modify: func(cfg *config.Settings) { cfg.Length = pinLengthMax + 1 },
+		},
+		{
+			name:   "Invalid number of passwords",
+			modify: func(cfg *config.Settings) { cfg.NumPasswords = numPasswordMax + 1 },
+		},
+		{
+			name:   "Below the minimum entropy",
+			modify: func(cfg *config.Settings) { cfg.MinEntropyBits = 14 },
+		},
+		{
+			name:   "Missing blocklist file",
+			modify: func(cfg *config.Settings) { cfg.BlocklistFile = "missing.txt" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := newPINTestSettings(pinLengthMin)
+			tt.modify(cfg)
+
+			if _, err := NewPINPasswordGeneratorService(cfg, &mockRNGService{}); err == nil {
+				t.Errorf("%s: NewPINPasswordGeneratorService() error = nil, want an error", tt.name)
+			}
+		})
+	}
+}
+
+func TestPINPasswordGeneratorServiceCalculate(t *testing.T) {
+	t.Parallel()
+
+	svc, err := NewPINPasswordGeneratorService(newPINTestSettings(pinLengthMin), &mockRNGService{})
+	if err != nil {
+		t.Fatalf("NewPINPasswordGeneratorService() error = %v", err)
+	}
+
+	got, err := svc.Calculate()
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+
+	want := Entropy{
+		BlindMin: 4 * math.Log2(10),
+		BlindMax: 4 * math.Log2(10),
+		Seen:     math.Log2(float64(10000 - len(svc.weak))),
+	}
+	if math.Abs(got.BlindMin-want.BlindMin) > entropyTolerance ||
+		math.Abs(got.BlindMax-want.BlindMax) > entropyTolerance ||
+		math.Abs(got.Seen-want.Seen) > entropyTolerance {
+		t.Errorf("Calculate() = %+v, want %+v", *got, want)
+	}
+
+	if got.Seen >= got.BlindMin {
+		t.Errorf("Calculate() Seen = %v, want it below %v once weak PINs are left out", got.Seen, got.BlindMin)
+	}
+}
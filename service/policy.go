@@ -0,0 +1,450 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+// The number of times a password is generated again when it breaks the
+// policy, before giving up
+const policyMaxAttempts int = 1000
+
+var ErrPolicyUnsatisfiable = errors.New("policy unsatisfiable with these settings")
+
+// PolicyUnsatisfiableError is returned when every attempt to generate a
+// password breaks the policy setting. It unwraps to ErrPolicyUnsatisfiable.
+type PolicyUnsatisfiableError struct {
+	// The number of passwords generated
+	Attempts int
+	// The rules broken and the number of attempts which broke each
+	Violations map[string]int
+}
+
+func (e *PolicyUnsatisfiableError) Error() string {
+	rules := make([]string, 0, len(e.Violations))
+	for _, rule := range slices.Sorted(maps.Keys(e.Violations)) {
+		rules = append(rules, fmt.Sprintf("%s (%d)", rule, e.Violations[rule]))
+	}
+
+	return fmt.Sprintf(
+		"%s, all %d attempts broke it: %s",
+		ErrPolicyUnsatisfiable,
+		e.Attempts,
+		strings.Join(rules, ", "),
+	)
+}
+
+func (e *PolicyUnsatisfiableError) Unwrap() error {
+	return ErrPolicyUnsatisfiable
+}
+
+// Checks the policy setting, if it is set, can be followed by a password. It
+// returns an error if a rule is invalid or the rules contradict each other.
+func validatePolicy(cfg *config.Settings) error {
+	p := cfg.Policy
+	if p == nil {
+		return nil
+	}
+
+	for _, rule := range []struct {
+		key string
+		n   int
+	}{
+		{option.PolicyKeyMaxLength, p.MaxLength},
+		{option.PolicyKeyMaxRepeatedCharacters, p.MaxRepeatedCharacters},
+		{option.PolicyKeyMinLength, p.MinLength},
+	} {
+		if rule.n < 0 {
+			return fmt.Errorf("%s %s (%d) must be greater than or equal to 0", option.ConfigKeyPolicy, rule.key, rule.n)
+		}
+	}
+
+	if p.MaxLength > 0 && p.MinLength > p.MaxLength {
+		return fmt.Errorf(
+			"%s %s (%d) cannot be greater than %s (%d)",
+			option.ConfigKeyPolicy,
+			option.PolicyKeyMinLength,
+			p.MinLength,
+			option.PolicyKeyMaxLength,
+			p.MaxLength,
+		)
+	}
+
+	total := 0
+	for _, req := range p.Required {
+		if err := validatePolicyRequirement(p, req); err != nil {
+			return err
+		}
+		total += req.Count
+	}
+
+	if p.MaxLength > 0 && total > p.MaxLength {
+		return fmt.Errorf(
+			"%s %s counts (%d) cannot add up to more than %s (%d)",
+			option.ConfigKeyPolicy,
+			option.PolicyKeyRequired,
+			total,
+			option.PolicyKeyMaxLength,
+			p.MaxLength,
+		)
+	}
+
+	return nil
+}
+
+// Checks a required character class of a policy. It returns an error if the
+// class is unknown, its count is below 1, or it has no characters which
+// aren't forbidden.
+func validatePolicyRequirement(p *config.Policy, req config.PolicyRequirement) error {
+	if !slices.Contains(option.CharacterClasses, req.Class) {
+		return fmt.Errorf("invalid %s %s class (%s)", option.ConfigKeyPolicy, option.PolicyKeyRequired, req.Class)
+	}
+
+	if req.Count < 1 {
+		return fmt.Errorf(
+			"%s %s count for %s (%d) must be greater than or equal to 1",
+			option.ConfigKeyPolicy,
+			option.PolicyKeyRequired,
+			policyRequirementName(req),
+			req.Count,
+		)
+	}
+
+	if req.Class != option.CharacterClassCustom {
+		if req.Characters != "" {
+			return fmt.Errorf("%s %s characters can only be set for the %s class", option.ConfigKeyPolicy, option.PolicyKeyRequired, option.CharacterClassCustom)
+		}
+
+		return nil
+	}
+
+	for _, r := range req.Characters {
+		if !strings.ContainsRune(p.ForbiddenCharacters, r) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf(
+		"%s %s class %s has no characters which aren't in %s",
+		option.ConfigKeyPolicy,
+		option.PolicyKeyRequired,
+		policyRequirementName(req),
+		option.PolicyKeyForbiddenCharacters,
+	)
+}
+
+// Checks the policy setting, if it is set, can be followed by a password drawn
+// only from the given characters, e.g. the digits of a PIN: each required
+// class needs one of them, and must_start_with_letter a letter. The source
+// names where the characters come from in errors. It returns an error for a
+// rule no password can follow.
+func validatePolicyCharacters(cfg *config.Settings, chars []string, source string) error {
+	p := cfg.Policy
+	if p == nil {
+		return nil
+	}
+
+	if p.MustStartWithLetter && !containsRune(chars, unicode.IsLetter) {
+		return fmt.Errorf("%s %s cannot be followed, %s has no letters", option.ConfigKeyPolicy, option.PolicyKeyMustStartWithLetter, source)
+	}
+
+	for _, req := range p.Required {
+		if !containsRune(chars, func(r rune) bool { return inPolicyClass(req, r) }) {
+			return fmt.Errorf(
+				"%s %s %s cannot be followed, %s has no characters of it",
+				option.ConfigKeyPolicy,
+				option.PolicyKeyRequired,
+				policyRequirementName(req),
+				source,
+			)
+		}
+	}
+
+	return nil
+}
+
+// Checks the policy setting, if it is set, against the words, separators and
+// padding of a DefaultPasswordGeneratorService. It returns an error for a rule
+// no password can follow: must_start_with_letter with padding digits or
+// symbols before the words, a letter case required which case_transform
+// leaves out of the words and nothing else adds, and forbidden_characters in
+// the separator or padding character every password contains.
+func validateWordPolicy(cfg *config.Settings) error {
+	if cfg.Policy == nil {
+		return nil
+	}
+
+	if err := validatePolicyStart(cfg); err != nil {
+		return err
+	}
+
+	if err := validatePolicyCase(cfg); err != nil {
+		return err
+	}
+
+	return validatePolicyForbidden(cfg)
+}
+
+// Checks must_start_with_letter can be followed when padding comes before the
+// words.
+func validatePolicyStart(cfg *config.Settings) error {
+	if !cfg.Policy.MustStartWithLetter {
+		return nil
+	}
+
+	if cfg.PaddingDigitsBefore > 0 {
+		return fmt.Errorf(
+			"%s %s cannot be followed when %s is %d",
+			option.ConfigKeyPolicy,
+			option.PolicyKeyMustStartWithLetter,
+			option.ConfigKeyPaddingDigitsBefore,
+			cfg.PaddingDigitsBefore,
+		)
+	}
+
+	if cfg.PaddingType == option.PaddingTypeFixed && cfg.PaddingCharactersBefore > 0 && !containsRune(paddingCharacters(cfg), unicode.IsLetter) {
+		return fmt.Errorf(
+			"%s %s cannot be followed when %s is %d",
+			option.ConfigKeyPolicy,
+			option.PolicyKeyMustStartWithLetter,
+			option.ConfigKeyPaddingCharactersBefore,
+			cfg.PaddingCharactersBefore,
+		)
+	}
+
+	return nil
+}
+
+// Checks a required letter case can be followed when case_transform leaves it
+// out of the words, which needs a separator, padding character or
+// substitution with a letter of that case.
+func validatePolicyCase(cfg *config.Settings) error {
+	var missing string
+	switch cfg.CaseTransform {
+	case option.CaseTransformLower:
+		missing = option.CharacterClassUpper
+	case option.CaseTransformUpper:
+		missing = option.CharacterClassLower
+	default:
+		return nil
+	}
+
+	idx := slices.IndexFunc(cfg.Policy.Required, func(req config.PolicyRequirement) bool { return req.Class == missing })
+	if idx < 0 {
+		return nil
+	}
+
+	others := slices.Concat(activeSeparators(cfg), slices.Collect(maps.Values(cfg.CharacterSubstitutions)))
+	if cfg.PaddingType != option.PaddingTypeNone {
+		others = append(others, paddingCharacters(cfg)...)
+	}
+
+	if containsRune(others, func(r rune) bool { return inPolicyClass(cfg.Policy.Required[idx], r) }) {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"%s %s %s cannot be followed when %s is %s",
+		option.ConfigKeyPolicy,
+		option.PolicyKeyRequired,
+		missing,
+		option.ConfigKeyCaseTransform,
+		cfg.CaseTransform,
+	)
+}
+
+// Checks forbidden_characters can be followed when every separator, or every
+// fixed padding character, contains one of them.
+func validatePolicyForbidden(cfg *config.Settings) error {
+	forbidden := cfg.Policy.ForbiddenCharacters
+	if forbidden == "" {
+		return nil
+	}
+
+	allForbidden := func(chars []string) bool {
+		return len(chars) > 0 && !slices.ContainsFunc(chars, func(c string) bool { return !strings.ContainsAny(c, forbidden) })
+	}
+
+	if allForbidden(activeSeparators(cfg)) {
+		what := option.ConfigKeySeparatorCharacter
+		if cfg.SeparatorCharacter == option.SeparatorCharacterRandom {
+			what = "every element of " + option.ConfigKeySeparatorAlphabet
+		}
+
+		return fmt.Errorf("%s %s cannot be followed, %s contains one of them", option.ConfigKeyPolicy, option.PolicyKeyForbiddenCharacters, what)
+	}
+
+	padded := cfg.PaddingCharactersBefore+cfg.PaddingCharactersAfter > 0
+	if cfg.PaddingType == option.PaddingTypeFixed && padded && allForbidden(paddingCharacters(cfg)) {
+		what := option.ConfigKeyPaddingCharacter
+		if cfg.PaddingCharacter == option.PaddingCharacterRandom {
+			what = "every element of " + option.ConfigKeySymbolAlphabet
+		}
+
+		return fmt.Errorf("%s %s cannot be followed, %s contains one of them", option.ConfigKeyPolicy, option.PolicyKeyForbiddenCharacters, what)
+	}
+
+	return nil
+}
+
+// Returns the characters padding is drawn from: the symbol alphabet when the
+// padding character is random, and the padding character otherwise.
+func paddingCharacters(cfg *config.Settings) []string {
+	if cfg.PaddingCharacter == option.PaddingCharacterRandom {
+		return effectiveAlphabet(cfg, cfg.SymbolAlphabet)
+	}
+
+	return []string{cfg.PaddingCharacter}
+}
+
+// Reports whether any rune of the strings satisfies f.
+func containsRune(strs []string, f func(rune) bool) bool {
+	return slices.ContainsFunc(strs, func(s string) bool { return strings.ContainsFunc(s, f) })
+}
+
+// Returns the name of a required character class in errors, with its
+// characters when it is CUSTOM, e.g. CUSTOM (!@#).
+func policyRequirementName(req config.PolicyRequirement) string {
+	if req.Class == option.CharacterClassCustom {
+		return fmt.Sprintf("%s (%s)", req.Class, req.Characters)
+	}
+
+	return req.Class
+}
+
+// Reports whether the rune belongs to the named character class of a
+// policy. SYMBOLS are the characters which aren't letters, digits or spaces,
+// and CUSTOM the characters of the requirement.
+func inPolicyClass(req config.PolicyRequirement, r rune) bool {
+	switch req.Class {
+	case option.CharacterClassLower:
+		return unicode.IsLower(r)
+	case option.CharacterClassUpper:
+		return unicode.IsUpper(r)
+	case option.CharacterClassDigits:
+		return unicode.IsDigit(r)
+	case option.CharacterClassSymbols:
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r)
+	}
+
+	return strings.ContainsRune(req.Characters, r)
+}
+
+// Returns the rules of the policy the password breaks, named by their policy
+// keys, or nil if it follows every rule. Lengths are counted in runes.
+func policyViolations(p *config.Policy, pw string) []string {
+	var broken []string
+	n := utf8.RuneCountInString(pw)
+
+	if n < p.MinLength {
+		broken = append(broken, option.PolicyKeyMinLength)
+	}
+
+	if p.MaxLength > 0 && n > p.MaxLength {
+		broken = append(broken, option.PolicyKeyMaxLength)
+	}
+
+	if p.ForbiddenCharacters != "" && strings.ContainsAny(pw, p.ForbiddenCharacters) {
+		broken = append(broken, option.PolicyKeyForbiddenCharacters)
+	}
+
+	if p.MaxRepeatedCharacters > 0 && longestRun(pw) > p.MaxRepeatedCharacters {
+		broken = append(broken, option.PolicyKeyMaxRepeatedCharacters)
+	}
+
+	if first, _ := utf8.DecodeRuneInString(pw); p.MustStartWithLetter && !unicode.IsLetter(first) {
+		broken = append(broken, option.PolicyKeyMustStartWithLetter)
+	}
+
+	return append(broken, brokenRequirements(p, pw)...)
+}
+
+// Returns the required character classes of the policy the password has too
+// few characters from, e.g. required UPPER.
+func brokenRequirements(p *config.Policy, pw string) []string {
+	var broken []string
+	for _, req := range p.Required {
+		count := 0
+		for _, r := range pw {
+			if inPolicyClass(req, r) {
+				count++
+			}
+		}
+
+		if count < req.Count {
+			broken = append(broken, fmt.Sprintf("%s %s", option.PolicyKeyRequired, policyRequirementName(req)))
+		}
+	}
+
+	return broken
+}
+
+// Returns the length of the longest run of the same rune in the string.
+func longestRun(s string) int {
+	longest, run := 0, 0
+	var prev rune
+	for i, r := range []rune(s) {
+		if i > 0 && r == prev {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+		prev = r
+	}
+
+	return longest
+}
+
+// Generates num_passwords passwords with generateOne, generating each again
+// while it breaks the policy setting, if it is set. It returns a
+// PolicyUnsatisfiableError if every one of policyMaxAttempts attempts breaks
+// it, or the first error generateOne returns.
+func generatePasswords(cfg *config.Settings, generateOne func() (string, error)) ([]string, error) {
+	pws := make([]string, cfg.NumPasswords)
+
+	for i := range pws {
+		pw, err := generateCompliant(cfg.Policy, generateOne)
+		if err != nil {
+			return nil, err
+		}
+
+		pws[i] = pw
+	}
+
+	return pws, nil
+}
+
+// Generates a single password with generateOne which follows the policy.
+func generateCompliant(p *config.Policy, generateOne func() (string, error)) (string, error) {
+	if p == nil {
+		return generateOne()
+	}
+
+	violations := make(map[string]int)
+	for range policyMaxAttempts {
+		pw, err := generateOne()
+		if err != nil {
+			return "", err
+		}
+
+		broken := policyViolations(p, pw)
+		if len(broken) == 0 {
+			return pw, nil
+		}
+
+		for _, rule := range broken {
+			violations[rule]++
+		}
+	}
+
+	return "", &PolicyUnsatisfiableError{Attempts: policyMaxAttempts, Violations: violations}
+}
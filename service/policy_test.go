@@ -0,0 +1,401 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+// A site policy of 12 to 40 characters with an uppercase letter, a digit and
+// one of !@#, which the default settings can follow.
+func newSitePolicy() *config.Policy {
+	return &config.Policy{
+		MinLength: 12,
+		MaxLength: 40,
+		Required: []config.PolicyRequirement{
+			{Class: option.CharacterClassUpper, Count: 1},
+			{Class: option.CharacterClassDigits, Count: 1},
+			{Class: option.CharacterClassCustom, Characters: "!@#", Count: 1},
+		},
+		MaxRepeatedCharacters: 2,
+	}
+}
+
+func TestPolicyViolations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		policy *config.Policy
+		pw     string
+		want   []string
+	}{
+		{
+			name:   "Follows the policy",
+			policy: newSitePolicy(),
+			pw:     "Correct-Horse7!x",
+			want:   nil,
+		},
+		{
+			name:   "Too short and missing classes",
+			policy: newSitePolicy(),
+			pw:     "horse",
+			want: []string{
+				option.PolicyKeyMinLength,
+				"required UPPER",
+				"required DIGITS",
+				"required CUSTOM (!@#)",
+			},
+		},
+		{
+			name:   "Too long",
+			policy: &config.Policy{MaxLength: 4},
+			pw:     "horses",
+			want:   []string{option.PolicyKeyMaxLength},
+		},
+		{
+			name:   "Repeated characters",
+			policy: &config.Policy{MaxRepeatedCharacters: 2},
+			pw:     "!!horsse!!!",
+			want:   []string{option.PolicyKeyMaxRepeatedCharacters},
+		},
+		{
+			name:   "Forbidden characters",
+			policy: &config.Policy{ForbiddenCharacters: `"\`},
+			pw:     `horse\battery`,
+			want:   []string{option.PolicyKeyForbiddenCharacters},
+		},
+		{
+			name:   "Starts with a digit",
+			policy: &config.Policy{MustStartWithLetter: true},
+			pw:     "12horse",
+			want:   []string{option.PolicyKeyMustStartWithLetter},
+		},
+		{
+			name:   "Starts with a letter",
+			policy: &config.Policy{MustStartWithLetter: true},
+			pw:     "Éclair12",
+			want:   nil,
+		},
+		{
+			name: "Symbols and lower case counted",
+			policy: &config.Policy{Required: []config.PolicyRequirement{
+				{Class: option.CharacterClassSymbols, Count: 2},
+				{Class: option.CharacterClassLower, Count: 6},
+			}},
+			pw:   "horse-battery staple",
+			want: []string{"required SYMBOLS"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := policyViolations(tt.policy, tt.pw); !slices.Equal(got, tt.want) {
+				t.Errorf("policyViolations(%q) = %v, want %v", tt.pw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePolicyErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		policy *config.Policy
+	}{
+		{name: "Negative minimum length", policy: &config.Policy{MinLength: -1}},
+		{name: "Negative maximum repeated", policy: &config.Policy{MaxRepeatedCharacters: -1}},
+		{name: "Minimum above maximum", policy: &config.Policy{MinLength: 20, MaxLength: 10}},
+		{
+			name:   "Unknown class",
+			policy: &config.Policy{Required: []config.PolicyRequirement{{Class: "EMOJI", Count: 1}}},
+		},
+		{
+			name:   "Count below 1",
+			policy: &config.Policy{Required: []config.PolicyRequirement{{Class: option.CharacterClassUpper}}},
+		},
+		{
+			name: "Characters of a named class",
+			policy: &config.Policy{Required: []config.PolicyRequirement{
+				{Class: option.CharacterClassDigits, Characters: "123", Count: 1},
+			}},
+		},
+		{
+			name: "Custom class forbidden",
+			policy: &config.Policy{
+				ForbiddenCharacters: "!@#",
+				Required:            []config.PolicyRequirement{{Class: option.CharacterClassCustom, Characters: "#@", Count: 1}},
+			},
+		},
+		{
+			name: "Counts above maximum length",
+			policy: &config.Policy{MaxLength: 4, Required: []config.PolicyRequirement{
+				{Class: option.CharacterClassUpper, Count: 3},
+				{Class: option.CharacterClassDigits, Count: 2},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := config.DefaultSettings()
+			cfg.Policy = tt.policy
+
+			if _, err := NewGeneratorService(cfg); err == nil {
+				t.Error("NewGeneratorService() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestPolicyContradictions(t *testing.T) {
+	t.Parallel()
+
+	requireClass := func(class string) *config.Policy {
+		return &config.Policy{Required: []config.PolicyRequirement{{Class: class, Count: 1}}}
+	}
+	startWithLetter := &config.Policy{MustStartWithLetter: true}
+
+	tests := []struct {
+		name    string
+		cfg     func(cfg *config.Settings)
+		wantErr bool
+	}{
+		{
+			name: "Upper case required with lower case words",
+			cfg: func(cfg *config.Settings) {
+				cfg.CaseTransform = option.CaseTransformLower
+				cfg.Policy = requireClass(option.CharacterClassUpper)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Lower case required with upper case words",
+			cfg: func(cfg *config.Settings) {
+				cfg.CaseTransform = option.CaseTransformUpper
+				cfg.Policy = requireClass(option.CharacterClassLower)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Upper case required with lower case words and an upper case separator",
+			cfg: func(cfg *config.Settings) {
+				cfg.CaseTransform = option.CaseTransformLower
+				cfg.SeparatorCharacter = "X"
+				cfg.Policy = requireClass(option.CharacterClassUpper)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Starting with a letter after padding digits",
+			cfg: func(cfg *config.Settings) {
+				cfg.PaddingDigitsBefore = 2
+				cfg.Policy = startWithLetter
+			},
+			wantErr: true,
+		},
+		{
+			name: "Starting with a letter after padding symbols",
+			cfg: func(cfg *config.Settings) {
+				cfg.PaddingDigitsBefore = 0
+				cfg.PaddingCharactersBefore = 1
+				cfg.Policy = startWithLetter
+			},
+			wantErr: true,
+		},
+		{
+			name: "Starting with a letter without padding before",
+			cfg: func(cfg *config.Settings) {
+				cfg.PaddingDigitsBefore = 0
+				cfg.PaddingCharactersBefore = 0
+				cfg.Policy = startWithLetter
+			},
+			wantErr: false,
+		},
+		{
+			name: "Forbidden separator character",
+			cfg: func(cfg *config.Settings) {
+				cfg.SeparatorCharacter = "-"
+				cfg.Policy = &config.Policy{ForbiddenCharacters: "-"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "Every separator forbidden",
+			cfg: func(cfg *config.Settings) {
+				cfg.SeparatorAlphabet = []string{"-", "."}
+				cfg.Policy = &config.Policy{ForbiddenCharacters: ".-"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "Forbidden padding character",
+			cfg: func(cfg *config.Settings) {
+				cfg.PaddingCharacter = "!"
+				cfg.Policy = &config.Policy{ForbiddenCharacters: "!"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "Pattern starting with a digit",
+			cfg: func(cfg *config.Settings) {
+				cfg.Pattern = "dd-W-w"
+				cfg.Policy = startWithLetter
+			},
+			wantErr: true,
+		},
+		{
+			name: "Upper case required of a PIN",
+			cfg: func(cfg *config.Settings) {
+				cfg.Generator = option.GeneratorPIN
+				cfg.Length = 6
+				cfg.Policy = requireClass(option.CharacterClassUpper)
+			},
+			wantErr: true,
+		},
+		{
+			name: "PIN starting with a letter",
+			cfg: func(cfg *config.Settings) {
+				cfg.Generator = option.GeneratorPIN
+				cfg.Length = 6
+				cfg.Policy = startWithLetter
+			},
+			wantErr: true,
+		},
+		{
+			name: "Class required which no character class has",
+			cfg: func(cfg *config.Settings) {
+				cfg.Generator = option.GeneratorCharacters
+				cfg.Length = 16
+				cfg.CharacterClasses = map[string]int{option.CharacterClassLower: 0}
+				cfg.Policy = requireClass(option.CharacterClassUpper)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := config.DefaultSettings()
+			tt.cfg(cfg)
+
+			if _, err := NewGeneratorService(cfg); (err != nil) != tt.wantErr {
+				t.Errorf("NewGeneratorService() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGeneratePolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		cfg  func() *config.Settings
+	}{
+		{
+			name: "Words",
+			cfg: func() *config.Settings {
+				cfg := config.DefaultSettings()
+				cfg.Policy = newSitePolicy()
+
+				return cfg
+			},
+		},
+		{
+			name: "Characters",
+			cfg: func() *config.Settings {
+				cfg := config.DefaultSettings()
+				cfg.Generator = option.GeneratorCharacters
+				cfg.Length = 16
+				cfg.CharacterClasses = map[string]int{option.CharacterClassLower: 0, option.CharacterClassDigits: 0}
+				cfg.Policy = &config.Policy{
+					MustStartWithLetter: true,
+					Required:            []config.PolicyRequirement{{Class: option.CharacterClassDigits, Count: 3}},
+				}
+
+				return cfg
+			},
+		},
+		{
+			name: "PIN",
+			cfg: func() *config.Settings {
+				cfg := config.DefaultSettings()
+				cfg.Generator = option.GeneratorPIN
+				cfg.Length = 6
+				cfg.Policy = &config.Policy{ForbiddenCharacters: "0", MaxRepeatedCharacters: 1}
+
+				return cfg
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := tt.cfg()
+			svc, err := NewGeneratorServiceWithRNG(cfg, NewSeededRNGService([]byte("policy")))
+			if err != nil {
+				t.Fatalf("NewGeneratorServiceWithRNG() error = %v", err)
+			}
+
+			for range presetContractIterations {
+				pws, err := svc.Generate()
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+
+				for _, pw := range pws {
+					if broken := policyViolations(cfg.Policy, pw); len(broken) > 0 {
+						t.Fatalf("Generate() password %q breaks %v", pw, broken)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGeneratePolicyUnsatisfiable(t *testing.T) {
+	t.Parallel()
+
+	// Without padding digits the passwords never contain a digit
+	cfg := config.DefaultSettings()
+	cfg.PaddingDigitsAfter = 0
+	cfg.PaddingDigitsBefore = 0
+	cfg.Policy = &config.Policy{Required: []config.PolicyRequirement{{Class: option.CharacterClassDigits, Count: 1}}}
+
+	svc, err := NewGeneratorServiceWithRNG(cfg, NewSeededRNGService([]byte("policy")))
+	if err != nil {
+		t.Fatalf("NewGeneratorServiceWithRNG() error = %v", err)
+	}
+
+	_, err = svc.Generate()
+	if !errors.Is(err, ErrPolicyUnsatisfiable) {
+		t.Fatalf("Generate() error = %v, want %v", err, ErrPolicyUnsatisfiable)
+	}
+
+	var policyErr *PolicyUnsatisfiableError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("Generate() error = %v, want a PolicyUnsatisfiableError", err)
+	}
+
+	if policyErr.Attempts != policyMaxAttempts {
+		t.Errorf("Attempts = %d, want %d", policyErr.Attempts, policyMaxAttempts)
+	}
+
+	want := map[string]int{"required DIGITS": policyMaxAttempts}
+	if len(policyErr.Violations) != len(want) || policyErr.Violations["required DIGITS"] != policyMaxAttempts {
+		t.Errorf("Violations = %v, want %v", policyErr.Violations, want)
+	}
+}
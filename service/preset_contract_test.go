@@ -16,6 +16,7 @@ const presetContractIterations = 25
 // entry only guarantee non-empty passwords.
 var presetLengthBounds = map[string]struct{ min, max int }{
 	option.PresetNTLM:          {14, 14},
+	option.PresetRandom32:      {32, 32},
 	option.PresetWeb16:         {0, 16},
 	option.PresetWeb16XKPasswd: {0, 16},
 	option.PresetWeb32:         {0, 32},
@@ -23,12 +24,12 @@ var presetLengthBounds = map[string]struct{ min, max int }{
 }
 
 // TestPresetContracts generates passwords from every embedded preset and
-// asserts the length guarantees documented in option.PresetDescriptionMap,
+// asserts the length guarantees documented in the asset.Presets descriptions,
 // so a preset edit which breaks a documented promise fails CI.
 func TestPresetContracts(t *testing.T) {
 	t.Parallel()
 
-	for _, preset := range option.Presets {
+	for _, preset := range asset.Presets.Names() {
 		t.Run(preset, func(t *testing.T) {
 			t.Parallel()
 
@@ -42,9 +43,9 @@ func TestPresetContracts(t *testing.T) {
 				t.Fatalf("config.New(%q) error = %v", preset, err)
 			}
 
-			svc, err := NewPasswordGeneratorService(cfg)
+			svc, err := NewGeneratorService(cfg)
 			if err != nil {
-				t.Fatalf("NewPasswordGeneratorService(%q) error = %v", preset, err)
+				t.Fatalf("NewGeneratorService(%q) error = %v", preset, err)
 			}
 
 			bounds, hasBounds := presetLengthBounds[preset]
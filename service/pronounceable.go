@@ -0,0 +1,357 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/eljamo/libpass/v8/asset"
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+const (
+	// The number of letters the next letter of a pseudo-word depends on
+	markovOrder int = 2
+	// Marks the start of a pseudo-word in a state, and its end as the next
+	// letter
+	markovBoundary rune = 0
+	// The number of times a pseudo-word is drawn again when it is too short,
+	// too long or blocked, before giving up
+	pronounceableMaxAttempts int = 10000
+	// Pseudo-words must fall within the word length range at least once in
+	// this many draws, so running out of attempts is practically impossible
+	pronounceableMinAcceptance int = 250
+)
+
+var ErrPronounceableWord = errors.New("failed to draw a pseudo-word within the word length range")
+
+// The last markovOrder letters of a pseudo-word, padded with markovBoundary at
+// the start.
+type markovState [markovOrder]rune
+
+// Returns the state after the given letter.
+func (s markovState) shift(r rune) markovState {
+	var next markovState
+	copy(next[:], s[1:])
+	next[markovOrder-1] = r
+
+	return next
+}
+
+// A letter which follows a state in the training words, and the number of
+// times it does.
+type markovTransition struct {
+	next  rune // markovBoundary when the word ends
+	count int
+}
+
+// A character-level Markov chain of order markovOrder, giving the letters
+// which follow each state in the training words and how often they do.
+type markovChain struct {
+	transitions map[markovState][]markovTransition // Ordered by letter
+	totals      map[markovState]int
+}
+
+// Trains a Markov chain on the given words, compared in lower case.
+func newMarkovChain(words []string) *markovChain {
+	counts := make(map[markovState]map[rune]int)
+	add := func(s markovState, r rune) {
+		if counts[s] == nil {
+			counts[s] = make(map[rune]int)
+		}
+		counts[s][r]++
+	}
+
+	for _, w := range words {
+		var s markovState
+		for _, r := range strings.ToLower(w) {
+			add(s, r)
+			s = s.shift(r)
+		}
+		add(s, markovBoundary)
+	}
+
+	c := &markovChain{
+		transitions: make(map[markovState][]markovTransition, len(counts)),
+		totals:      make(map[markovState]int, len(counts)),
+	}
+	for s, next := range counts {
+		for _, r := range slices.Sorted(maps.Keys(next)) {
+			c.transitions[s] = append(c.transitions[s], markovTransition{r, next[r]})
+			c.totals[s] += next[r]
+		}
+	}
+
+	return c
+}
+
+// Draws the letter which follows the state, weighted by how often it follows
+// it in the training words.
+func (c *markovChain) next(s markovState, rngSvc RNGService) (rune, error) {
+	n, err := rngSvc.GenerateWithMax(c.totals[s])
+	if err != nil {
+		return 0, err
+	}
+
+	ts := c.transitions[s]
+	for _, t := range ts {
+		if n < t.count {
+			return t.next, nil
+		}
+		n -= t.count
+	}
+
+	return ts[len(ts)-1].next, nil
+}
+
+// Returns the probability a walk of the chain ends with a pseudo-word of
+// between minLen and maxLen letters, and the probability of the most likely
+// of those pseudo-words. Every pseudo-word is reached by a single walk, so
+// its probability is the product of the transitions along it.
+func (c *markovChain) lengthProbabilities(minLen int, maxLen int) (float64, float64) {
+	type prob struct{ sum, max float64 }
+
+	var accept prob
+	cur := map[markovState]prob{{}: {1, 1}}
+	for n := 0; n <= maxLen && len(cur) > 0; n++ {
+		next := make(map[markovState]prob)
+		for s, p := range cur {
+			total := float64(c.totals[s])
+			for _, t := range c.transitions[s] {
+				q := float64(t.count) / total
+				if t.next == markovBoundary {
+					if n >= minLen {
+						accept.sum += p.sum * q
+						accept.max = max(accept.max, p.max*q)
+					}
+					continue
+				}
+
+				if n == maxLen {
+					continue
+				}
+
+				ns := s.shift(t.next)
+				np := next[ns]
+				np.sum += p.sum * q
+				np.max = max(np.max, p.max*q)
+				next[ns] = np
+			}
+		}
+		cur = next
+	}
+
+	return accept.sum, accept.max
+}
+
+// PronounceableWordListService implements the WordListService interface,
+// generating pronounceable pseudo-words instead of drawing words from a word
+// list. Each letter is drawn from a Markov chain trained on the word list,
+// weighted by how often it follows the two letters before it, so the
+// pseudo-words sound like the words they are trained on, e.g. MIDDLE_EARTH
+// gives fantasy-sounding words. Pseudo-words outside the word length range or
+// blocked by the blocklist are drawn again.
+type PronounceableWordListService struct {
+	cfg       *config.Settings
+	rngSvc    RNGService
+	chain     *markovChain
+	wordList  []string // The training words within the word length range
+	blocklist *blocklist
+	wordBits  float64 // The seen entropy of a pseudo-word
+}
+
+// Creates a new instance of PronounceableWordListService, trained on the words
+// of the file set in word_list_file if there is one, and of the embedded word
+// list set in word_list otherwise, once word_charset, the blocklist and
+// avoid_ambiguous are applied. It returns an error if the configuration is
+// invalid.
+func NewPronounceableWordListService(cfg *config.Settings, rngSvc RNGService) (*PronounceableWordListService, error) {
+	if err := validatePronounceable(cfg); err != nil {
+		return nil, err
+	}
+
+	bl, err := newBlocklist(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := newWordFilter(cfg, bl)
+	if err != nil {
+		return nil, err
+	}
+
+	source := fmt.Sprintf("%s (%s)", option.ConfigKeyWordList, cfg.WordList)
+	load := func(minLen, maxLen int) ([]string, error) {
+		return asset.GetWordListView(cfg.WordList, minLen, maxLen)
+	}
+	if cfg.WordListFile != "" {
+		source = fmt.Sprintf("%s (%s)", option.ConfigKeyWordListFile, cfg.WordListFile)
+		load = func(minLen, maxLen int) ([]string, error) {
+			return asset.GetFilteredWordListFromFile(cfg.WordListFile, minLen, maxLen)
+		}
+	}
+
+	words, err := getWordList(source, 1, math.MaxInt, load, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	svc := &PronounceableWordListService{
+		cfg:       cfg,
+		rngSvc:    rngSvc,
+		chain:     newMarkovChain(words),
+		wordList:  filterWordList(words, func(w string) bool { return wordLengthInRange(cfg, w) }),
+		blocklist: bl,
+	}
+
+	if len(svc.wordList) == 0 {
+		return nil, fmt.Errorf(
+			"no words in %s are between %s (%d) and %s (%d) letters long",
+			source,
+			option.ConfigKeyWordLengthMin,
+			cfg.WordLengthMin,
+			option.ConfigKeyWordLengthMax,
+			cfg.WordLengthMax,
+		)
+	}
+
+	accept, most := svc.chain.lengthProbabilities(cfg.WordLengthMin, cfg.WordLengthMax)
+	if accept*float64(pronounceableMinAcceptance) < 1 {
+		return nil, fmt.Errorf(
+			"fewer than 1 in %d pseudo-words trained on %s are between %s (%d) and %s (%d) letters long",
+			pronounceableMinAcceptance,
+			source,
+			option.ConfigKeyWordLengthMin,
+			cfg.WordLengthMin,
+			option.ConfigKeyWordLengthMax,
+			cfg.WordLengthMax,
+		)
+	}
+	svc.wordBits = math.Log2(accept / most)
+
+	return svc, nil
+}
+
+// Checks the configuration of a PronounceableWordListService, which draws
+// every word from a single model and cannot draw words without replacement.
+func validatePronounceable(cfg *config.Settings) error {
+	if cfg.NumWords < numWordMin {
+		return fmt.Errorf("%s must be greater than or equal to %d", option.ConfigKeyNumWords, numWordMin)
+	}
+
+	if cfg.WordLengthMin < 1 || cfg.WordLengthMax < cfg.WordLengthMin {
+		return fmt.Errorf(
+			"%s must be at least 1 and %s at least %s",
+			option.ConfigKeyWordLengthMin,
+			option.ConfigKeyWordLengthMax,
+			option.ConfigKeyWordLengthMin,
+		)
+	}
+
+	for _, opt := range []struct {
+		key string
+		set bool
+	}{
+		{option.ConfigKeyUniqueWords, cfg.UniqueWords},
+		{option.ConfigKeyWordListSlots, len(cfg.WordListSlots) > 0},
+		{option.ConfigKeyWordListWeights, len(cfg.WordListWeights) > 0},
+	} {
+		if opt.set {
+			return fmt.Errorf("%s cannot be set when %s is %s", opt.key, option.ConfigKeyGenerator, option.GeneratorPronounceable)
+		}
+	}
+
+	return nil
+}
+
+// Reports whether the word is within the word length range in runes.
+func wordLengthInRange(cfg *config.Settings, word string) bool {
+	n := utf8.RuneCountInString(word)
+
+	return n >= cfg.WordLengthMin && n <= cfg.WordLengthMax
+}
+
+// GetWords returns num_words pseudo-words, drawn again if adjacent
+// pseudo-words form a blocked word.
+func (s *PronounceableWordListService) GetWords() ([]string, error) {
+	return s.blocklist.drawWords(s.getWords)
+}
+
+func (s *PronounceableWordListService) getWords() ([]string, error) {
+	words := make([]string, 0, s.cfg.NumWords)
+	for range s.cfg.NumWords {
+		w, err := s.generateWord()
+		if err != nil {
+			return nil, err
+		}
+		words = append(words, w)
+	}
+
+	return words, nil
+}
+
+// Walks the Markov chain until it gives a pseudo-word within the word length
+// range which isn't blocked. It returns ErrPronounceableWord if every attempt
+// fails.
+func (s *PronounceableWordListService) generateWord() (string, error) {
+	for range pronounceableMaxAttempts {
+		var state markovState
+		var word []rune
+		for len(word) <= s.cfg.WordLengthMax {
+			r, err := s.chain.next(state, s.rngSvc)
+			if err != nil {
+				return "", err
+			}
+
+			if r == markovBoundary {
+				break
+			}
+
+			word = append(word, r)
+			state = state.shift(r)
+		}
+
+		if len(word) < s.cfg.WordLengthMin || len(word) > s.cfg.WordLengthMax {
+			continue
+		}
+
+		if w := string(word); s.blocklist == nil || !s.blocklist.blocks(w) {
+			return w, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w after %d attempts", ErrPronounceableWord, pronounceableMaxAttempts)
+}
+
+// WordList returns the words the Markov chain is trained on which are within
+// the word length range, which share their letters with the pseudo-words. A
+// pseudo-word can take any length in the word length range, whatever the
+// lengths of the training words.
+func (s *PronounceableWordListService) WordList() []string {
+	return slices.Clone(s.wordList)
+}
+
+// Returns the training words without copying them.
+func (s *PronounceableWordListService) words() []string {
+	return s.wordList
+}
+
+// Returns the length in runes of the shortest and longest pseudo-words, the
+// word length range.
+func (s *PronounceableWordListService) lengthRange() (int, int) {
+	return s.cfg.WordLengthMin, s.cfg.WordLengthMax
+}
+
+// WordEntropy returns the seen entropy in bits of the pseudo-words in a
+// password. As pseudo-words aren't equally likely, it is the min-entropy: the
+// entropy of the most likely pseudo-word within the word length range. The
+// pseudo-words the blocklist rejects lower it by a negligible amount, which
+// is ignored.
+func (s *PronounceableWordListService) WordEntropy() float64 {
+	return float64(s.cfg.NumWords) * s.wordBits
+}
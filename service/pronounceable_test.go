@@ -0,0 +1,213 @@
+package service
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/eljamo/libpass/v8/asset"
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+const pronounceableTestWordList = "TEST_PRONOUNCEABLE"
+
+// The chain trained on these words gives bab, bac and bad with probability
+// 1/4 each, and bbb, bbbb, ... with probability 1/2^(n+1) for n letters.
+var registerPronounceableTestWordList = sync.OnceValue(func() error {
+	return asset.WordLists.Register(
+		pronounceableTestWordList,
+		"Pronounceable test words",
+		asset.BytesSource([]byte("bab\nbac\nbad\nbbb\n")),
+	)
+})
+
+func newPronounceableTestSettings(t *testing.T) *config.Settings {
+	t.Helper()
+
+	if err := registerPronounceableTestWordList(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	return &config.Settings{
+		Generator: option.GeneratorPronounceable, NumWords: 2,
+		WordList: pronounceableTestWordList, WordLengthMin: 3, WordLengthMax: 3,
+		PaddingType: option.PaddingTypeNone,
+	}
+}
+
+func TestPronounceableWordListServiceGetWords(t *testing.T) {
+	t.Parallel()
+
+	cfg := newPronounceableTestSettings(t)
+	cfg.WordLengthMax = 5
+
+	svc, err := NewPronounceableWordListService(cfg, NewSeededRNGService([]byte("pronounceable")))
+	if err != nil {
+		t.Fatalf("NewPronounceableWordListService() error = %v", err)
+	}
+
+	valid := []string{"bab", "bac", "bad", "bbb", "bbbb", "bbbbb"}
+	for range presetContractIterations {
+		words, err := svc.GetWords()
+		if err != nil {
+			t.Fatalf("GetWords() error = %v", err)
+		}
+
+		if len(words) != cfg.NumWords {
+			t.Fatalf("GetWords() returned %d words, want %d", len(words), cfg.NumWords)
+		}
+
+		for _, w := range words {
+			if !slices.Contains(valid, w) {
+				t.Fatalf("GetWords() returned %q, want one of %v", w, valid)
+			}
+		}
+	}
+
+	errSvc, err := NewPronounceableWordListService(cfg, &mockErrRNGService{})
+	if err != nil {
+		t.Fatalf("NewPronounceableWordListService() error = %v", err)
+	}
+
+	if _, err := errSvc.GetWords(); err == nil {
+		t.Error("GetWords() error = nil, want the RNG error")
+	}
+}
+
+func TestPronounceableWordListServiceWordEntropy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		maxLen int
+		want   float64
+	}{
+		{
+			// bab, bac and bad are the most likely of the 13/16 of
+			// pseudo-words which are 3 letters long
+			name:   "Three letters",
+			maxLen: 3,
+			want:   2 * math.Log2(13.0/16/(1.0/4)),
+		},
+		{
+			name:   "Three or four letters",
+			maxLen: 4,
+			want:   2 * math.Log2((13.0/16+1.0/32)/(1.0/4)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := newPronounceableTestSettings(t)
+			cfg.WordLengthMax = tt.maxLen
+
+			svc, err := NewPronounceableWordListService(cfg, &mockRNGService{})
+			if err != nil {
+				t.Fatalf("NewPronounceableWordListService() error = %v", err)
+			}
+
+			if got := svc.WordEntropy(); math.Abs(got-tt.want) > entropyTolerance {
+				t.Errorf("WordEntropy() = %v, want %v", got, tt.want)
+			}
+
+			es, err := NewEntropyServiceFromWordList(cfg, svc)
+			if err != nil {
+				t.Fatalf("NewEntropyServiceFromWordList() error = %v", err)
+			}
+
+			got, err := es.Calculate()
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+
+			if math.Abs(got.Seen-tt.want) > entropyTolerance {
+				t.Errorf("Calculate() Seen = %v, want %v", got.Seen, tt.want)
+			}
+
+			// The pseudo-words can be longer than the 3 letter training words
+			wantMax := float64(cfg.NumWords*tt.maxLen) * math.Log2(26)
+			if math.Abs(got.BlindMax-wantMax) > entropyTolerance {
+				t.Errorf("Calculate() BlindMax = %v, want %v", got.BlindMax, wantMax)
+			}
+		})
+	}
+}
+
+func TestNewPronounceableWordListServiceErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		modify func(cfg *config.Settings)
+	}{
+		{
+			name:   "Too few words",
+			modify: func(cfg *config.Settings) { cfg.NumWords = 1 },
+		},
+		{
+			name:   "Maximum length below the minimum",
+			modify: func(cfg *config.Settings) { cfg.WordLengthMax = 2 },
+		},
+		{
+			name:   "No words of the given length",
+			modify: func(cfg *config.Settings) { cfg.WordLengthMin, cfg.WordLengthMax = 6, 8 },
+		},
+		{
+			name:   "Unique words",
+			modify: func(cfg *config.Settings) { cfg.UniqueWords = true },
+		},
+		{
+			name: "Word list slots",
+			modify: func(cfg *config.Settings) {
+				cfg.WordListSlots = []string{pronounceableTestWordList, pronounceableTestWordList}
+			},
+		},
+		{
+			name:   "Unknown word list",
+			modify: func(cfg *config.Settings) { cfg.WordList = "MISSING" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := newPronounceableTestSettings(t)
+			tt.modify(cfg)
+
+			if _, err := NewPronounceableWordListService(cfg, &mockRNGService{}); err == nil {
+				t.Errorf("%s: NewPronounceableWordListService() error = nil, want an error", tt.name)
+			}
+		})
+	}
+}
+
+func TestNewGeneratorServicePronounceable(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.DefaultSettings()
+	cfg.Generator = option.GeneratorPronounceable
+	cfg.WordList = option.WordListMiddleEarth
+
+	svc, err := NewGeneratorServiceWithRNG(cfg, NewSeededRNGService([]byte("pronounceable")))
+	if err != nil {
+		t.Fatalf("NewGeneratorServiceWithRNG() error = %v", err)
+	}
+
+	pgs, ok := svc.(*DefaultPasswordGeneratorService)
+	if !ok {
+		t.Fatalf("NewGeneratorServiceWithRNG() = %T, want *DefaultPasswordGeneratorService", svc)
+	}
+
+	if _, ok := pgs.wordListSvc.(*PronounceableWordListService); !ok {
+		t.Errorf("wordListSvc = %T, want *PronounceableWordListService", pgs.wordListSvc)
+	}
+
+	if _, err := svc.Generate(); err != nil {
+		t.Errorf("Generate() error = %v", err)
+	}
+}
@@ -2,20 +2,28 @@ package service
 
 import (
 	"crypto/rand"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
 	"math"
-	"math/big"
+	"math/bits"
+	"sync"
 )
 
 const (
-	maxInt   int = math.MaxInt // Maximum value for an int variable for the build architecture
-	maxDigit int = 10          // Maximum digit value, used in GenerateDigit
+	maxInt        int = math.MaxInt // Maximum value for an int variable for the build architecture
+	maxDigit      int = 10          // Maximum digit value, used in GenerateDigit
+	rngBufferSize int = 512         // Number of random bytes read from the entropy source at a time, a multiple of 8
 )
 
+// The entropy source used when no reader is given
+var defaultEntropySource = rand.Reader
+
 var (
 	ErrRNGMaxLessThanOne          = errors.New("rng max cannot be less than 1")
 	ErrRNGSliceLengthLessThanZero = errors.New("rng slice length cannot be less than 0")
+	ErrRNGHealthTestFailed        = errors.New("rng health test failed")
 )
 
 // RNGService defines an interface for random number generation.
@@ -33,26 +41,75 @@ type RNGService interface {
 	GenerateSliceWithMax(length int, max int) ([]int, error)
 }
 
-// DefaultRNGService is a struct implementing the RNGService interface.
-type DefaultRNGService struct{}
+// DefaultRNGService is a struct implementing the RNGService interface. It
+// reads from its entropy source, crypto/rand unless another reader is given,
+// in blocks of rngBufferSize bytes and turns each 8 bytes into a bounded
+// number without allocating. It is safe for concurrent use, and its zero
+// value is ready to use.
+type DefaultRNGService struct {
+	mu     sync.Mutex
+	src    io.Reader // Entropy source, crypto/rand.Reader when nil
+	buf    [rngBufferSize]byte
+	unread int // Number of bytes at the end of buf which have not been used
+}
 
 // Creates a new instance of DefaultRNGService.
 func NewRNGService() *DefaultRNGService {
 	return &DefaultRNGService{}
 }
 
+// Creates a new instance of DefaultRNGService which reads from the given
+// entropy source instead of crypto/rand, e.g. a vetted DRBG. The reader must
+// produce uniformly random bytes; any error it returns is surfaced by the
+// Generate methods, and the service itself takes care of serialising reads.
+// It panics if the reader is nil, rather than quietly using crypto/rand; use
+// NewRNGService for that.
+func NewRNGServiceFromReader(r io.Reader) *DefaultRNGService {
+	if r == nil {
+		panic("service: nil reader passed to NewRNGServiceFromReader, use NewRNGService to read from crypto/rand")
+	}
+
+	return &DefaultRNGService{src: r}
+}
+
+// Returns the next 64-bit value from the buffer, refilling it from the
+// entropy source when it is empty. Used bytes are wiped from the buffer.
+func (s *DefaultRNGService) next() (uint64, error) {
+	if s.unread == 0 {
+		src := s.src
+		if src == nil {
+			src = defaultEntropySource
+		}
+
+		if _, err := io.ReadFull(src, s.buf[:]); err != nil {
+			return 0, err
+		}
+		s.unread = rngBufferSize
+	}
+
+	off := rngBufferSize - s.unread
+	x := binary.LittleEndian.Uint64(s.buf[off:])
+	clear(s.buf[off : off+8])
+	s.unread -= 8
+
+	return x, nil
+}
+
 // Generates a random integer up to the specified maximum value.
 func (s *DefaultRNGService) GenerateWithMax(max int) (int, error) {
 	if max < 1 {
 		return 0, ErrRNGMaxLessThanOne
 	}
 
-	n, err := rand.Int(rand.Reader, big.NewInt(int64(max)))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, err := boundedUint64(uint64(max), s.next)
 	if err != nil {
 		return 0, fmt.Errorf("failed to generate random number: %w", err)
 	}
 
-	return int(n.Int64()), nil
+	return int(n), nil
 }
 
 // Generates a random integer with the maximum possible value for int.
@@ -67,6 +124,17 @@ func (s *DefaultRNGService) GenerateDigit() (int, error) {
 
 // Generates a slice of random integers, each up to the specified maximum value.
 func (s *DefaultRNGService) GenerateSliceWithMax(length int, max int) ([]int, error) {
+	return generateSliceWithMax(length, max, s.GenerateWithMax)
+}
+
+// Generates a slice of random integers with the maximum possible value for int.
+func (s *DefaultRNGService) GenerateSlice(length int) ([]int, error) {
+	return s.GenerateSliceWithMax(length, maxInt)
+}
+
+// Generates a slice of random integers, each up to the specified maximum value,
+// using the given function to generate each integer.
+func generateSliceWithMax(length int, max int, generateWithMax func(int) (int, error)) ([]int, error) {
 	if length < 0 {
 		return nil, ErrRNGSliceLengthLessThanZero
 	}
@@ -81,7 +149,7 @@ func (s *DefaultRNGService) GenerateSliceWithMax(length int, max int) ([]int, er
 
 	slice := make([]int, length)
 	for i := range length {
-		n, err := s.GenerateWithMax(max)
+		n, err := generateWithMax(max)
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate random number for slice at index %d: %w", i, err)
 		}
@@ -91,7 +159,26 @@ func (s *DefaultRNGService) GenerateSliceWithMax(length int, max int) ([]int, er
 	return slice, nil
 }
 
-// Generates a slice of random integers with the maximum possible value for int.
-func (s *DefaultRNGService) GenerateSlice(length int) ([]int, error) {
-	return s.GenerateSliceWithMax(length, maxInt)
+// Returns a uniformly distributed integer in [0, n) from the 64-bit values
+// returned by next, using Lemire's multiply-and-reject method so that no
+// value is favoured over another.
+func boundedUint64(n uint64, next func() (uint64, error)) (uint64, error) {
+	x, err := next()
+	if err != nil {
+		return 0, err
+	}
+
+	hi, lo := bits.Mul64(x, n)
+	if lo < n {
+		threshold := -n % n
+		for lo < threshold {
+			x, err = next()
+			if err != nil {
+				return 0, err
+			}
+			hi, lo = bits.Mul64(x, n)
+		}
+	}
+
+	return hi, nil
 }
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"fmt"
+	"io"
+)
+
+// Cutoffs for the NIST SP 800-90B continuous health tests, run on every byte
+// read from the entropy source. They assume a conservative min-entropy of 1
+// bit per byte and a false positive rate of 2^-20 per test, so a healthy
+// source practically never trips them while a stuck or heavily biased one
+// does within a few hundred bytes.
+const (
+	repetitionCountCutoff    int = 21  // 1 + ceil(20 / H), the number of identical bytes in a row which fails the test
+	adaptiveProportionWindow int = 512 // Number of bytes in each adaptive proportion test window
+	adaptiveProportionCutoff int = 311 // Occurrences of a window's first byte within the window which fail the test
+)
+
+// healthCheckedReader wraps an entropy source and runs the repetition count
+// and adaptive proportion tests on the bytes it reads. Once a test fails the
+// reader fails closed: the offending read and every read after it return an
+// error wrapping ErrRNGHealthTestFailed. It is not safe for concurrent use,
+// DefaultRNGService serialises its reads.
+type healthCheckedReader struct {
+	src io.Reader
+	err error // The health test failure, returned by every read once set
+
+	rctValue byte // The byte being repeated
+	rctCount int  // Number of times rctValue has been seen in a row
+
+	aptValue byte // The first byte of the current window
+	aptCount int  // Number of times aptValue has been seen in the current window
+	aptSeen  int  // Number of bytes seen in the current window
+}
+
+// Creates a new instance of DefaultRNGService which runs continuous health
+// tests on the bytes read from the given entropy source, or crypto/rand if it
+// is nil. If the source looks stuck or biased, every Generate method fails
+// with an error wrapping ErrRNGHealthTestFailed instead of returning
+// predictable numbers.
+func NewHealthCheckedRNGService(r io.Reader) *DefaultRNGService {
+	return NewRNGServiceFromReader(&healthCheckedReader{src: r})
+}
+
+// Read reads from the entropy source and tests every byte read. Bytes from a
+// read which fails a test are wiped and not returned.
+func (r *healthCheckedReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	src := r.src
+	if src == nil {
+		src = defaultEntropySource
+	}
+
+	n, err := src.Read(p)
+	for _, b := range p[:n] {
+		if testErr := r.test(b); testErr != nil {
+			r.err = testErr
+			clear(p[:n])
+
+			return 0, testErr
+		}
+	}
+
+	return n, err
+}
+
+// Runs both health tests on the next byte from the entropy source.
+func (r *healthCheckedReader) test(b byte) error {
+	if err := r.repetitionCountTest(b); err != nil {
+		return err
+	}
+
+	return r.adaptiveProportionTest(b)
+}
+
+// Fails if the same byte is seen repetitionCountCutoff times in a row, which
+// detects a source that has become stuck on a single value.
+func (r *healthCheckedReader) repetitionCountTest(b byte) error {
+	if r.rctCount > 0 && b == r.rctValue {
+		r.rctCount++
+		if r.rctCount >= repetitionCountCutoff {
+			return fmt.Errorf("%w: repetition count test saw %#02x %d times in a row", ErrRNGHealthTestFailed, b, r.rctCount)
+		}
+
+		return nil
+	}
+
+	r.rctValue = b
+	r.rctCount = 1
+
+	return nil
+}
+
+// Fails if the first byte of a window of adaptiveProportionWindow bytes is
+// seen adaptiveProportionCutoff times within it, which detects a source that
+// has become heavily biased towards a single value.
+func (r *healthCheckedReader) adaptiveProportionTest(b byte) error {
+	if r.aptSeen == 0 {
+		r.aptValue = b
+		r.aptCount = 1
+		r.aptSeen = 1
+
+		return nil
+	}
+
+	if b == r.aptValue {
+		r.aptCount++
+		if r.aptCount >= adaptiveProportionCutoff {
+			return fmt.Errorf("%w: adaptive proportion test saw %#02x %d times in %d bytes", ErrRNGHealthTestFailed, b, r.aptCount, adaptiveProportionWindow)
+		}
+	}
+
+	r.aptSeen++
+	if r.aptSeen == adaptiveProportionWindow {
+		r.aptSeen = 0
+	}
+
+	return nil
+}
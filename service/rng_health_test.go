@@ -0,0 +1,143 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/eljamo/libpass/v8/config"
+)
+
+// constantReader is a stuck entropy source which only ever returns one byte.
+type constantReader byte
+
+func (r constantReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(r)
+	}
+
+	return len(p), nil
+}
+
+// biasedReader is an entropy source which returns zero for all but every
+// tenth byte, so it never repeats a byte enough times in a row to fail the
+// repetition count test but is heavily biased.
+type biasedReader struct {
+	n int
+}
+
+func (r *biasedReader) Read(p []byte) (int, error) {
+	for i := range p {
+		r.n++
+		if r.n%10 == 0 {
+			p[i] = byte(r.n / 10)
+		} else {
+			p[i] = 0
+		}
+	}
+
+	return len(p), nil
+}
+
+// countingReader is a healthy entropy source which returns every byte value
+// in turn.
+type countingReader struct {
+	n byte
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = r.n
+		r.n++
+	}
+
+	return len(p), nil
+}
+
+func TestHealthCheckedRNGService(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		src     func() *healthCheckedReader
+		wantErr bool
+	}{
+		{
+			name:    "Healthy source",
+			src:     func() *healthCheckedReader { return &healthCheckedReader{src: &countingReader{}} },
+			wantErr: false,
+		},
+		{
+			name:    "Default source",
+			src:     func() *healthCheckedReader { return &healthCheckedReader{} },
+			wantErr: false,
+		},
+		{
+			name:    "Stuck source fails the repetition count test",
+			src:     func() *healthCheckedReader { return &healthCheckedReader{src: constantReader(0x2a)} },
+			wantErr: true,
+		},
+		{
+			name:    "Biased source fails the adaptive proportion test",
+			src:     func() *healthCheckedReader { return &healthCheckedReader{src: &biasedReader{}} },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rngSvc := NewRNGServiceFromReader(tt.src())
+
+			var err error
+			for range 1000 {
+				if _, err = rngSvc.GenerateWithMax(100); err != nil {
+					break
+				}
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GenerateWithMax() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr && !errors.Is(err, ErrRNGHealthTestFailed) {
+				t.Errorf("GenerateWithMax() error = %v, want ErrRNGHealthTestFailed", err)
+			}
+		})
+	}
+}
+
+func TestHealthCheckedReaderFailsClosed(t *testing.T) {
+	t.Parallel()
+
+	r := &healthCheckedReader{src: constantReader(0)}
+	p := make([]byte, repetitionCountCutoff)
+	if _, err := r.Read(p); !errors.Is(err, ErrRNGHealthTestFailed) {
+		t.Fatalf("Read() error = %v, want ErrRNGHealthTestFailed", err)
+	}
+
+	// A source which recovers must not be trusted again
+	r.src = &countingReader{}
+	n, err := r.Read(p)
+	if n != 0 || !errors.Is(err, ErrRNGHealthTestFailed) {
+		t.Errorf("Read() after a failure = %d, %v, want 0, ErrRNGHealthTestFailed", n, err)
+	}
+}
+
+func TestHealthCheckedRNGServiceFailsPasswordGeneration(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := config.New()
+	if err != nil {
+		t.Fatalf("config.New() error = %v", err)
+	}
+
+	svc, err := NewPasswordGeneratorServiceWithRNG(cfg, NewHealthCheckedRNGService(constantReader(0)))
+	if err != nil {
+		t.Fatalf("NewPasswordGeneratorServiceWithRNG() error = %v", err)
+	}
+
+	if _, err := svc.Generate(); !errors.Is(err, ErrRNGHealthTestFailed) {
+		t.Errorf("Generate() error = %v, want ErrRNGHealthTestFailed", err)
+	}
+}
@@ -1,9 +1,15 @@
 package service
 
 import (
+	"crypto/rand"
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"strings"
 	"testing"
+
+	"github.com/eljamo/libpass/v8/config"
 )
 
 type mockRNGService struct{}
@@ -281,3 +287,228 @@ func TestGenerateDigit(t *testing.T) {
 		}
 	}
 }
+
+func TestBoundedUint64(t *testing.T) {
+	t.Parallel()
+
+	sequence := func(values ...uint64) func() (uint64, error) {
+		return func() (uint64, error) {
+			if len(values) == 0 {
+				return 0, errMockRNGService
+			}
+			v := values[0]
+			values = values[1:]
+
+			return v, nil
+		}
+	}
+
+	tests := []struct {
+		name    string
+		n       uint64
+		next    func() (uint64, error)
+		want    uint64
+		wantErr bool
+	}{
+		{
+			name: "Zero maps to zero for a power of two",
+			n:    8,
+			next: sequence(0),
+			want: 0,
+		},
+		{
+			name: "Maximum maps to the top of the range",
+			n:    10,
+			next: sequence(math.MaxUint64),
+			want: 9,
+		},
+		{
+			// 2^64 mod 3 is 1, so the single value whose low product is 0
+			// would favour 0 and must be rejected
+			name: "Biased value is rejected",
+			n:    3,
+			next: sequence(0, math.MaxUint64),
+			want: 2,
+		},
+		{
+			name:    "Source error",
+			n:       10,
+			next:    sequence(),
+			wantErr: true,
+		},
+		{
+			name:    "Source error after rejection",
+			n:       3,
+			next:    sequence(0),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := boundedUint64(tt.n, tt.next)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("boundedUint64() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("boundedUint64() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestZeroValueRNGService(t *testing.T) {
+	t.Parallel()
+
+	var rngSvc DefaultRNGService
+	seen := make(map[int]bool)
+	for range 1000 {
+		n, err := rngSvc.GenerateWithMax(4)
+		if err != nil {
+			t.Fatalf("GenerateWithMax() error = %v", err)
+		}
+		seen[n] = true
+	}
+
+	if len(seen) != 4 {
+		t.Errorf("GenerateWithMax(4) produced %v over 1000 calls, want all of 0-3", seen)
+	}
+}
+
+var errMockReader = errors.New("mock reader error")
+
+// mockReader returns the bytes in data and then fails with errMockReader.
+type mockReader struct {
+	data []byte
+}
+
+func (r *mockReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, errMockReader
+	}
+
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+
+	return n, nil
+}
+
+func TestRNGServiceFromReader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Reads numbers from the reader", func(t *testing.T) {
+		t.Parallel()
+
+		// Every 64-bit value is 0, which maps to 0 for a power of two
+		rngSvc := NewRNGServiceFromReader(&mockReader{data: make([]byte, rngBufferSize)})
+		for range rngBufferSize / 8 {
+			n, err := rngSvc.GenerateWithMax(16)
+			if err != nil {
+				t.Fatalf("GenerateWithMax() error = %v", err)
+			}
+			if n != 0 {
+				t.Fatalf("GenerateWithMax() = %d, want 0", n)
+			}
+		}
+
+		if _, err := rngSvc.GenerateWithMax(16); !errors.Is(err, errMockReader) {
+			t.Errorf("GenerateWithMax() after the reader is exhausted error = %v, want errMockReader", err)
+		}
+	})
+
+	t.Run("Nil reader", func(t *testing.T) {
+		t.Parallel()
+
+		defer func() {
+			if recover() == nil {
+				t.Error("NewRNGServiceFromReader(nil) didn't panic")
+			}
+		}()
+
+		NewRNGServiceFromReader(nil)
+	})
+
+	t.Run("Short reader", func(t *testing.T) {
+		t.Parallel()
+
+		rngSvc := NewRNGServiceFromReader(&mockReader{data: make([]byte, 8)})
+		_, err := rngSvc.GenerateDigit()
+		if !errors.Is(err, errMockReader) {
+			t.Errorf("GenerateDigit() error = %v, want errMockReader", err)
+		}
+	})
+
+	t.Run("Failing reader fails password generation", func(t *testing.T) {
+		t.Parallel()
+
+		cfg, err := config.New()
+		if err != nil {
+			t.Fatalf("config.New() error = %v", err)
+		}
+
+		svc, err := NewPasswordGeneratorServiceWithRNG(cfg, NewRNGServiceFromReader(&mockReader{}))
+		if err != nil {
+			t.Fatalf("NewPasswordGeneratorServiceWithRNG() error = %v", err)
+		}
+
+		_, err = svc.Generate()
+		if !errors.Is(err, errMockReader) {
+			t.Fatalf("Generate() error = %v, want errMockReader", err)
+		}
+
+		if !strings.Contains(err.Error(), "failed to generate random number") {
+			t.Errorf("Generate() error = %q, want it to contain %q", err, "failed to generate random number")
+		}
+	})
+}
+
+func BenchmarkRNGGenerateWithMax(b *testing.B) {
+	rngSvc := NewRNGService()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := rngSvc.GenerateWithMax(7776); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkBigIntGenerateWithMax measures the crypto/rand.Int approach the
+// buffered DefaultRNGService replaced, as a baseline for
+// BenchmarkRNGGenerateWithMax.
+func BenchmarkBigIntGenerateWithMax(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := rand.Int(rand.Reader, big.NewInt(7776)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRNGGenerateSliceWithMax(b *testing.B) {
+	rngSvc := NewRNGService()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := rngSvc.GenerateSliceWithMax(8, 7776); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRNGGenerateWithMaxParallel(b *testing.B) {
+	rngSvc := NewRNGService()
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := rngSvc.GenerateWithMax(7776); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
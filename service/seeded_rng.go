@@ -0,0 +1,65 @@
+package service
+
+import (
+	"crypto/sha256"
+	"math/rand/v2"
+	"sync"
+)
+
+// SeededRNGService is an RNGService backed by a ChaCha8 stream keyed by a
+// seed, so the same seed always produces the same sequence of numbers and the
+// same passwords. It is meant for reproducible runs such as golden tests, bug
+// reports and demos; passwords generated from a seed are only as secret as
+// the seed itself.
+type SeededRNGService struct {
+	mu  sync.Mutex
+	src *rand.ChaCha8
+}
+
+// Creates a new instance of SeededRNGService. The seed can be of any length,
+// it is hashed with SHA-256 to derive the ChaCha8 key.
+func NewSeededRNGService(seed []byte) *SeededRNGService {
+	return &SeededRNGService{src: rand.NewChaCha8(sha256.Sum256(seed))}
+}
+
+// Returns the next 64-bit value from the ChaCha8 stream.
+func (s *SeededRNGService) next() (uint64, error) {
+	return s.src.Uint64(), nil
+}
+
+// Generates a random integer up to the specified maximum value.
+func (s *SeededRNGService) GenerateWithMax(max int) (int, error) {
+	if max < 1 {
+		return 0, ErrRNGMaxLessThanOne
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, err := boundedUint64(uint64(max), s.next)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(n), nil
+}
+
+// Generates a random integer with the maximum possible value for int.
+func (s *SeededRNGService) Generate() (int, error) {
+	return s.GenerateWithMax(maxInt)
+}
+
+// GenerateDigit generates a single digit (0-9).
+func (s *SeededRNGService) GenerateDigit() (int, error) {
+	return s.GenerateWithMax(maxDigit)
+}
+
+// Generates a slice of random integers, each up to the specified maximum value.
+func (s *SeededRNGService) GenerateSliceWithMax(length int, max int) ([]int, error) {
+	return generateSliceWithMax(length, max, s.GenerateWithMax)
+}
+
+// Generates a slice of random integers with the maximum possible value for int.
+func (s *SeededRNGService) GenerateSlice(length int) ([]int, error) {
+	return s.GenerateSliceWithMax(length, maxInt)
+}
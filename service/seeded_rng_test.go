@@ -0,0 +1,93 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/eljamo/libpass/v8/config"
+)
+
+func TestSeededRNGGenerateSliceWithMaxIsStable(t *testing.T) {
+	t.Parallel()
+
+	// Pinned so a change to the derivation of the key or to the sampling of
+	// bounded numbers, which would break reproducibility of previously
+	// recorded seeds, fails CI.
+	want := []int{71, 31, 88, 43, 58, 60, 60, 23}
+
+	got, err := NewSeededRNGService([]byte("libpass")).GenerateSliceWithMax(len(want), 100)
+	if err != nil {
+		t.Fatalf("GenerateSliceWithMax() error = %v", err)
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("GenerateSliceWithMax() = %v, want %v", got, want)
+	}
+}
+
+func TestSeededRNGGenerateWithMax(t *testing.T) {
+	t.Parallel()
+
+	rngSvc := NewSeededRNGService([]byte("bounds"))
+
+	if _, err := rngSvc.GenerateWithMax(0); !errors.Is(err, ErrRNGMaxLessThanOne) {
+		t.Errorf("GenerateWithMax(0) error = %v, want ErrRNGMaxLessThanOne", err)
+	}
+
+	if _, err := rngSvc.GenerateSliceWithMax(-1, 10); !errors.Is(err, ErrRNGSliceLengthLessThanZero) {
+		t.Errorf("GenerateSliceWithMax(-1, 10) error = %v, want ErrRNGSliceLengthLessThanZero", err)
+	}
+
+	for range 1000 {
+		digit, err := rngSvc.GenerateDigit()
+		if err != nil {
+			t.Fatalf("GenerateDigit() error = %v", err)
+		}
+		if digit < 0 || digit > 9 {
+			t.Fatalf("GenerateDigit() = %d, want 0-9", digit)
+		}
+
+		n, err := rngSvc.Generate()
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if n < 0 {
+			t.Fatalf("Generate() = %d, want a non-negative number", n)
+		}
+	}
+}
+
+func TestSeededPasswordGeneratorIsReproducible(t *testing.T) {
+	t.Parallel()
+
+	generate := func(seed string) []string {
+		t.Helper()
+
+		cfg, err := config.New()
+		if err != nil {
+			t.Fatalf("config.New() error = %v", err)
+		}
+
+		svc, err := NewPasswordGeneratorServiceWithRNG(cfg, NewSeededRNGService([]byte(seed)))
+		if err != nil {
+			t.Fatalf("NewPasswordGeneratorServiceWithRNG() error = %v", err)
+		}
+
+		pws, err := svc.Generate()
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+
+		return pws
+	}
+
+	first, second := generate("reproducible"), generate("reproducible")
+	if !slices.Equal(first, second) {
+		t.Errorf("Generate() with the same seed = %v and %v, want equal passwords", first, second)
+	}
+
+	if other := generate("different"); slices.Equal(first, other) {
+		t.Errorf("Generate() with different seeds both = %v, want different passwords", first)
+	}
+}
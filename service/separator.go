@@ -2,33 +2,36 @@ package service
 
 import (
 	"fmt"
-	"unicode/utf8"
 
 	"github.com/eljamo/libpass/v8/config"
 	"github.com/eljamo/libpass/v8/config/option"
-	"github.com/eljamo/libpass/v8/internal/validator"
 )
 
 // Defines the interface for a service that can separate elements of a string
 // slice.
 type SeparatorService interface {
-	// Separate takes a slice of strings and inserts a separator character
-	// between each element of the slice or returns an error if the slice
-	// cannot be separated
+	// Separate takes a slice of strings and inserts a separator between each
+	// element of the slice or returns an error if the slice cannot be
+	// separated
 	Separate(slice []string) ([]string, error)
 }
 
 // Implements the SeparatorService, providing functionality to separate string
 // slices.
 type DefaultSeparatorService struct {
-	cfg    *config.Settings
-	rngSvc RNGService
+	cfg      *config.Settings
+	rngSvc   RNGService
+	alphabet []string // The separators a random separator is drawn from
 }
 
 // Creates a new instance of DefaultSeparatorService. It validates the provided
 // configuration and returns an error if the configuration is invalid.
 func NewSeparatorService(cfg *config.Settings, rngSvc RNGService) (*DefaultSeparatorService, error) {
-	svc := &DefaultSeparatorService{cfg, rngSvc}
+	svc := &DefaultSeparatorService{
+		cfg:      cfg,
+		rngSvc:   rngSvc,
+		alphabet: effectiveAlphabet(cfg, cfg.SeparatorAlphabet),
+	}
 
 	if err := svc.validate(); err != nil {
 		return nil, err
@@ -37,21 +40,30 @@ func NewSeparatorService(cfg *config.Settings, rngSvc RNGService) (*DefaultSepar
 	return svc, nil
 }
 
-// Separate takes a slice of strings and inserts a separator character between
-// each element of the slice. The separator character is determined based on the
-// configuration. It returns the modified slice or an error if the separator
-// character cannot be determined.
+// Separate takes a slice of strings and inserts a separator between each
+// element of the slice and at its edges. The separator is determined based on
+// the configuration, and a random separator is drawn once for the whole slice,
+// or for each gap when separator_mode is PER_GAP. It returns the modified
+// slice or an error if a separator cannot be determined.
 func (s *DefaultSeparatorService) Separate(slice []string) ([]string, error) {
-	char, err := s.getSeparatorCharacter()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get separator character: %w", err)
+	seps := make([]string, 1)
+	if s.cfg.SeparatorMode == option.SeparatorModePerGap {
+		seps = make([]string, len(slice)+1)
+	}
+
+	for i := range seps {
+		char, err := s.getSeparatorCharacter()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get separator character: %w", err)
+		}
+		seps[i] = char
 	}
 
-	separatedSlice := make([]string, 0, len(slice))
-	for _, element := range slice {
-		separatedSlice = append(separatedSlice, char, element)
+	separatedSlice := make([]string, 0, 2*len(slice)+1)
+	for i, element := range slice {
+		separatedSlice = append(separatedSlice, seps[min(i, len(seps)-1)], element)
 	}
-	separatedSlice = append(separatedSlice, char)
+	separatedSlice = append(separatedSlice, seps[len(seps)-1])
 
 	return separatedSlice, nil
 }
@@ -61,36 +73,51 @@ func (s *DefaultSeparatorService) Separate(slice []string) ([]string, error) {
 // alphabet. Returns an error if it fails to return a random character.
 func (s *DefaultSeparatorService) getSeparatorCharacter() (string, error) {
 	if s.cfg.SeparatorCharacter == option.SeparatorCharacterRandom {
-		num, err := s.rngSvc.GenerateWithMax(len(s.cfg.SeparatorAlphabet))
+		num, err := s.rngSvc.GenerateWithMax(len(s.alphabet))
 		if err != nil {
 			return "", fmt.Errorf("failed to generate random number for separator character: %w", err)
 		}
 
-		return s.cfg.SeparatorAlphabet[num], nil
+		return s.alphabet[num], nil
 	}
 
 	return s.cfg.SeparatorCharacter, nil
 }
 
-// Checks the configuration of the DefaultSeparatorService for  correctness.
-// It ensures that the separator character is either a single  character or a
-// valid random character from the alphabet. Returns an error if the
-// configuration is invalid.
+// Checks the configuration of the DefaultSeparatorService for correctness.
+// It ensures that the separator mode is known, a random separator can be
+// drawn from the alphabet, and PER_GAP is only set with a random separator.
+// Separators may be several characters long, e.g. " - " or "::". Returns an
+// error if the configuration is invalid.
 func (s *DefaultSeparatorService) validate() error {
-	if s.cfg.SeparatorCharacter != option.SeparatorCharacterRandom && utf8.RuneCountInString(s.cfg.SeparatorCharacter) > 1 {
-		return fmt.Errorf("%s must be a single character if specified", option.ConfigKeySeparatorCharacter)
-	}
-
-	if s.cfg.SeparatorCharacter == option.SeparatorCharacterRandom {
-		if len(s.cfg.SeparatorAlphabet) == 0 {
-			return fmt.Errorf("%s cannot be empty", option.ConfigKeySeparatorAlphabet)
+	random := s.cfg.SeparatorCharacter == option.SeparatorCharacterRandom
+
+	switch s.cfg.SeparatorMode {
+	case "", option.SeparatorModeSingle:
+	case option.SeparatorModePerGap:
+		if !random {
+			return fmt.Errorf(
+				"%s must be %s when %s is %s",
+				option.ConfigKeySeparatorCharacter,
+				option.SeparatorCharacterRandom,
+				option.ConfigKeySeparatorMode,
+				option.SeparatorModePerGap,
+			)
 		}
+	default:
+		return fmt.Errorf("invalid %s value (%s)", option.ConfigKeySeparatorMode, s.cfg.SeparatorMode)
+	}
 
-		chk := validator.HasElementWithLengthGreaterThanOne(s.cfg.SeparatorAlphabet)
-		if chk {
-			return fmt.Errorf("%s cannot contain elements with a length greater than 1", option.ConfigKeySeparatorAlphabet)
-		}
+	if random && len(s.cfg.SeparatorAlphabet) == 0 {
+		return fmt.Errorf("%s cannot be empty", option.ConfigKeySeparatorAlphabet)
 	}
 
-	return nil
+	return validateUnambiguous(
+		s.cfg,
+		option.ConfigKeySeparatorCharacter,
+		s.cfg.SeparatorCharacter,
+		random,
+		option.ConfigKeySeparatorAlphabet,
+		s.cfg.SeparatorAlphabet,
+	)
 }
@@ -24,9 +24,9 @@ func TestNewSeparatorService(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name:    "Invalid configuration - invalid separator character",
-			cfg:     &config.Settings{SeparatorCharacter: "invalid"},
-			wantErr: true,
+			name:    "Valid configuration - multi-character separator character",
+			cfg:     &config.Settings{SeparatorCharacter: " - "},
+			wantErr: false,
 		},
 		{
 			name:    "Valid configuration - separator alphabet",
@@ -39,9 +39,14 @@ func TestNewSeparatorService(t *testing.T) {
 			cfg:     &config.Settings{SeparatorCharacter: option.SeparatorCharacterRandom, SeparatorAlphabet: []string{"a"}},
 			wantErr: false,
 		},
+		{
+			name:    "Valid configuration - multi-character separator alphabet",
+			cfg:     &config.Settings{SeparatorCharacter: option.SeparatorCharacterRandom, SeparatorAlphabet: []string{"::", "aaa"}},
+			wantErr: false,
+		},
 		{
 			name:    "Invalid configuration - empty separator alphabet",
-			cfg:     &config.Settings{SeparatorCharacter: option.SeparatorCharacterRandom, SeparatorAlphabet: []string{"aaa"}},
+			cfg:     &config.Settings{SeparatorCharacter: option.SeparatorCharacterRandom},
 			wantErr: true,
 		},
 		{
@@ -55,8 +60,27 @@ func TestNewSeparatorService(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name:    "Invalid configuration - two-rune separator character",
+			name:    "Valid configuration - two-rune separator character",
 			cfg:     &config.Settings{SeparatorCharacter: "€€"},
+			wantErr: false,
+		},
+		{
+			name: "Valid configuration - per gap separator mode",
+			cfg: &config.Settings{
+				SeparatorCharacter: option.SeparatorCharacterRandom,
+				SeparatorAlphabet:  []string{"!", "-"},
+				SeparatorMode:      option.SeparatorModePerGap,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Invalid configuration - per gap separator mode with a fixed separator",
+			cfg:     &config.Settings{SeparatorCharacter: "-", SeparatorMode: option.SeparatorModePerGap},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid configuration - unknown separator mode",
+			cfg:     &config.Settings{SeparatorCharacter: "-", SeparatorMode: "EVERY_OTHER"},
 			wantErr: true,
 		},
 	}
@@ -115,6 +139,13 @@ func TestSeparatorServiceSeparate(t *testing.T) {
 			input:    []string{"a", "b", "c"},
 			expected: []string{"=", "a", "=", "b", "=", "c", "="},
 		},
+		{
+			name:     "With multi-character separator",
+			cfg:      &config.Settings{SeparatorCharacter: " - "},
+			rngSvc:   rngs,
+			input:    []string{"a", "b"},
+			expected: []string{" - ", "a", " - ", "b", " - "},
+		},
 	}
 
 	// Run test cases
@@ -126,6 +157,44 @@ func TestSeparatorServiceSeparate(t *testing.T) {
 	}
 }
 
+func TestSeparatorServiceSeparatePerGap(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Settings{
+		SeparatorCharacter: option.SeparatorCharacterRandom,
+		SeparatorAlphabet:  []string{"!", "-", "=", "::"},
+		SeparatorMode:      option.SeparatorModePerGap,
+	}
+
+	svc, err := NewSeparatorService(cfg, NewSeededRNGService([]byte("separator")))
+	if err != nil {
+		t.Fatalf("NewSeparatorService() error = %v", err)
+	}
+
+	words := slices.Repeat([]string{"a"}, 16)
+	got, err := svc.Separate(words)
+	if err != nil {
+		t.Fatalf("Separate() error = %v", err)
+	}
+
+	if len(got) != 2*len(words)+1 {
+		t.Fatalf("Separate() returned %d elements, want %d", len(got), 2*len(words)+1)
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i < len(got); i += 2 {
+		if !slices.Contains(cfg.SeparatorAlphabet, got[i]) {
+			t.Errorf("Separate() separator %q isn't in the separator alphabet", got[i])
+		}
+		seen[got[i]] = true
+	}
+
+	// A single separator would be repeated in all 17 gaps
+	if len(seen) < 2 {
+		t.Errorf("Separate() drew the same separator for every gap: %v", got)
+	}
+}
+
 // Helper function to run the test cases
 func runSeparatorServiceSeparateTest(t *testing.T, cfg *config.Settings, rngSvc RNGService, input []string, expected []string, expectErr error) {
 	t.Helper()
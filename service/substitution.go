@@ -0,0 +1,240 @@
+package service
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+// Defines the interface for a service that substitutes characters of the words
+// of a password.
+type SubstitutionService interface {
+	// Substitute takes a slice of words and replaces their characters as
+	// configured, or returns an error
+	Substitute(slice []string) ([]string, error)
+}
+
+// Implements the SubstitutionService, replacing the characters of the words
+// with those of the character_substitutions setting, e.g. a with @.
+type DefaultSubstitutionService struct {
+	cfg           *config.Settings
+	rngSvc        RNGService
+	substitutions map[rune]string
+}
+
+// Creates a new instance of DefaultSubstitutionService. It validates the
+// provided configuration and returns an error if the configuration is invalid.
+func NewSubstitutionService(cfg *config.Settings, rngSvc RNGService) (*DefaultSubstitutionService, error) {
+	svc := &DefaultSubstitutionService{cfg: cfg, rngSvc: rngSvc}
+
+	if err := svc.validate(); err != nil {
+		return nil, err
+	}
+
+	svc.substitutions = substitutionRunes(cfg)
+
+	return svc, nil
+}
+
+// Returns the character substitutions keyed by the lower case rune they
+// replace.
+func substitutionRunes(cfg *config.Settings) map[rune]string {
+	subs := make(map[rune]string, len(cfg.CharacterSubstitutions))
+	for k, v := range cfg.CharacterSubstitutions {
+		r, _ := utf8.DecodeRuneInString(k)
+		subs[unicode.ToLower(r)] = v
+	}
+
+	return subs
+}
+
+// The position of a character which can be substituted, its word and the
+// index of the rune in the word
+type substitutable struct {
+	word int
+	char int
+}
+
+// Substitute takes a slice of words, once the case transformation has been
+// applied, and replaces each character of character_substitutions whatever
+// its case. With character_substitution_mode ALWAYS, the default, every
+// occurrence is replaced, with RANDOM each occurrence is replaced or not at
+// random, and with AT_LEAST_ONE one occurrence drawn at random is replaced
+// when RANDOM would replace none. The input slice is not modified; a
+// substituted copy is returned.
+func (s *DefaultSubstitutionService) Substitute(slice []string) ([]string, error) {
+	words := make([][]rune, len(slice))
+	var found []substitutable
+	for i, w := range slice {
+		words[i] = []rune(w)
+		for j, r := range words[i] {
+			if _, ok := s.substitutions[unicode.ToLower(r)]; ok {
+				found = append(found, substitutable{i, j})
+			}
+		}
+	}
+
+	chosen, err := s.choose(len(found))
+	if err != nil {
+		return nil, fmt.Errorf("failed to choose characters to substitute: %w", err)
+	}
+
+	replaced := make(map[substitutable]bool, len(found))
+	for i, pos := range found {
+		replaced[pos] = chosen[i]
+	}
+
+	out := make([]string, len(slice))
+	for i, w := range words {
+		var sb strings.Builder
+		for j, r := range w {
+			if replaced[substitutable{i, j}] {
+				sb.WriteString(s.substitutions[unicode.ToLower(r)])
+				continue
+			}
+			sb.WriteRune(r)
+		}
+		out[i] = sb.String()
+	}
+
+	return out, nil
+}
+
+// Chooses which of the given number of substitutable characters are replaced,
+// as character_substitution_mode sets.
+func (s *DefaultSubstitutionService) choose(n int) ([]bool, error) {
+	chosen := make([]bool, n)
+	if s.cfg.CharacterSubstitutionMode == "" || s.cfg.CharacterSubstitutionMode == option.CharacterSubstitutionModeAlways {
+		for i := range chosen {
+			chosen[i] = true
+		}
+
+		return chosen, nil
+	}
+
+	for i := range chosen {
+		num, err := s.rngSvc.GenerateWithMax(2)
+		if err != nil {
+			return nil, err
+		}
+		chosen[i] = num == 1
+	}
+
+	if s.cfg.CharacterSubstitutionMode == option.CharacterSubstitutionModeAtLeastOne && n > 0 && !slices.Contains(chosen, true) {
+		num, err := s.rngSvc.GenerateWithMax(n)
+		if err != nil {
+			return nil, err
+		}
+		chosen[num] = true
+	}
+
+	return chosen, nil
+}
+
+// Checks character_substitutions isn't set for a generator which doesn't
+// substitute characters, rather than ignoring it. The key and value name the
+// setting which chose the generator, e.g. generator and CHARACTERS.
+func validateNoSubstitutions(cfg *config.Settings, key string, value string) error {
+	if len(cfg.CharacterSubstitutions) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%s cannot be set when %s is %s", option.ConfigKeyCharacterSubstitutions, key, value)
+}
+
+// Returns the separators a substitution cannot share a character with: the
+// separator alphabet when the separator is random, and the separator
+// character otherwise.
+func activeSeparators(cfg *config.Settings) []string {
+	if cfg.SeparatorCharacter == option.SeparatorCharacterRandom {
+		return effectiveAlphabet(cfg, cfg.SeparatorAlphabet)
+	}
+
+	return []string{cfg.SeparatorCharacter}
+}
+
+// Checks the configuration of the DefaultSubstitutionService. Each character
+// to replace must be a single character, listed once whatever its case, and
+// replaced with something other than itself. A replacement cannot contain a
+// character of the separators, which would make the words and separators
+// hard to tell apart, nor an ambiguous character when avoid_ambiguous is
+// set. Returns an error if the configuration is invalid.
+func (s *DefaultSubstitutionService) validate() error {
+	if s.cfg.CharacterSubstitutionMode != "" && !slices.Contains(option.CharacterSubstitutionModes, s.cfg.CharacterSubstitutionMode) {
+		return fmt.Errorf("invalid %s value (%s)", option.ConfigKeyCharacterSubstitutionMode, s.cfg.CharacterSubstitutionMode)
+	}
+
+	seen := make(map[rune]bool, len(s.cfg.CharacterSubstitutions))
+	for _, k := range slices.Sorted(maps.Keys(s.cfg.CharacterSubstitutions)) {
+		if err := s.validateSubstitution(k, s.cfg.CharacterSubstitutions[k]); err != nil {
+			return err
+		}
+
+		r, _ := utf8.DecodeRuneInString(k)
+		if seen[unicode.ToLower(r)] {
+			return fmt.Errorf("%s cannot list %s more than once, characters are matched case-insensitively", option.ConfigKeyCharacterSubstitutions, k)
+		}
+		seen[unicode.ToLower(r)] = true
+	}
+
+	return nil
+}
+
+// Checks a single character substitution, the character to replace and its
+// replacement.
+func (s *DefaultSubstitutionService) validateSubstitution(k string, v string) error {
+	if utf8.RuneCountInString(k) != 1 {
+		return fmt.Errorf("%s keys must be single characters (%s)", option.ConfigKeyCharacterSubstitutions, k)
+	}
+
+	if v == "" || strings.EqualFold(k, v) {
+		return fmt.Errorf("%s value for %s (%s) must replace it with other characters", option.ConfigKeyCharacterSubstitutions, k, v)
+	}
+
+	for _, sep := range activeSeparators(s.cfg) {
+		if sep != "" && strings.ContainsAny(v, sep) {
+			return fmt.Errorf(
+				"%s value for %s (%s) collides with the separator %s",
+				option.ConfigKeyCharacterSubstitutions,
+				k,
+				v,
+				sep,
+			)
+		}
+	}
+
+	if s.cfg.AvoidAmbiguous && isAmbiguous(v) {
+		return fmt.Errorf("%s value for %s (%s) is ambiguous and %s is set", option.ConfigKeyCharacterSubstitutions, k, v, option.ConfigKeyAvoidAmbiguous)
+	}
+
+	return nil
+}
+
+// Returns the fewest characters of any word of the word list which
+// character_substitutions can replace, matched case-insensitively.
+func minSubstitutable(cfg *config.Settings, wordList []string) int {
+	subs := substitutionRunes(cfg)
+	if len(subs) == 0 || len(wordList) == 0 {
+		return 0
+	}
+
+	fewest := math.MaxInt
+	for _, w := range wordList {
+		n := 0
+		for _, r := range w {
+			if _, ok := subs[unicode.ToLower(r)]; ok {
+				n++
+			}
+		}
+		fewest = min(fewest, n)
+	}
+
+	return fewest
+}
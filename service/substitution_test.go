@@ -0,0 +1,284 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/eljamo/libpass/v8/config"
+	"github.com/eljamo/libpass/v8/config/option"
+)
+
+func TestNewSubstitutionService(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cfg     *config.Settings
+		wantErr bool
+	}{
+		{
+			name:    "Valid configuration - no substitutions",
+			cfg:     &config.Settings{SeparatorCharacter: "-"},
+			wantErr: false,
+		},
+		{
+			name: "Valid configuration - default substitutions",
+			cfg: &config.Settings{
+				CharacterSubstitutions: option.DefaultCharacterSubstitutions,
+				SeparatorCharacter:     "-",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Valid configuration - multi-character replacement",
+			cfg: &config.Settings{
+				CharacterSubstitutionMode: option.CharacterSubstitutionModeAtLeastOne,
+				CharacterSubstitutions:    map[string]string{"w": `\/\/`},
+				SeparatorCharacter:        "-",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid configuration - unknown mode",
+			cfg: &config.Settings{
+				CharacterSubstitutionMode: "SOMETIMES",
+				CharacterSubstitutions:    map[string]string{"a": "@"},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid configuration - key longer than a character",
+			cfg:     &config.Settings{CharacterSubstitutions: map[string]string{"ph": "f"}},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid configuration - empty replacement",
+			cfg:     &config.Settings{CharacterSubstitutions: map[string]string{"a": ""}},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid configuration - replaced with itself",
+			cfg:     &config.Settings{CharacterSubstitutions: map[string]string{"a": "A"}},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid configuration - character listed in both cases",
+			cfg:     &config.Settings{CharacterSubstitutions: map[string]string{"a": "@", "A": "4"}},
+			wantErr: true,
+		},
+		{
+			name: "Invalid configuration - collides with the separator alphabet",
+			cfg: &config.Settings{
+				CharacterSubstitutions: option.DefaultCharacterSubstitutions,
+				SeparatorCharacter:     option.SeparatorCharacterRandom,
+				SeparatorAlphabet:      []string{"-", "$"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid configuration - collides with the separator character",
+			cfg: &config.Settings{
+				CharacterSubstitutions: map[string]string{"a": "@-"},
+				SeparatorCharacter:     "-",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid configuration - ambiguous replacement",
+			cfg: &config.Settings{
+				AvoidAmbiguous:         true,
+				CharacterSubstitutions: map[string]string{"o": "0"},
+				SeparatorCharacter:     "-",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewSubstitutionService(tt.cfg, &mockRNGService{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSubstitutionService() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSubstitute(t *testing.T) {
+	t.Parallel()
+
+	subs := map[string]string{"a": "@", "e": "3", "o": "0", "s": "$"}
+
+	tests := []struct {
+		name   string
+		mode   string
+		rngSvc RNGService
+		input  []string
+		want   []string
+	}{
+		{
+			name:   "Always, whatever the case",
+			mode:   option.CharacterSubstitutionModeAlways,
+			rngSvc: &mockRNGService{},
+			input:  []string{"horse", "BATTERY", "Staple", "crwth"},
+			want:   []string{"h0r$3", "B@TT3RY", "$t@pl3", "crwth"},
+		},
+		{
+			name:   "Always by default",
+			rngSvc: &mockRNGService{},
+			input:  []string{"horse"},
+			want:   []string{"h0r$3"},
+		},
+		{
+			name:   "Random, every occurrence drawn",
+			mode:   option.CharacterSubstitutionModeRandom,
+			rngSvc: &mockRNGService{},
+			input:  []string{"horse", "staple"},
+			want:   []string{"h0r$3", "$t@pl3"},
+		},
+		{
+			name:   "Random, no occurrence drawn",
+			mode:   option.CharacterSubstitutionModeRandom,
+			rngSvc: &mockEvenRNGService{},
+			input:  []string{"horse", "staple"},
+			want:   []string{"horse", "staple"},
+		},
+		{
+			name:   "At least one, when none is drawn",
+			mode:   option.CharacterSubstitutionModeAtLeastOne,
+			rngSvc: &mockEvenRNGService{},
+			input:  []string{"horse", "staple"},
+			want:   []string{"hors3", "staple"},
+		},
+		{
+			name:   "At least one, with nothing to substitute",
+			mode:   option.CharacterSubstitutionModeAtLeastOne,
+			rngSvc: &mockEvenRNGService{},
+			input:  []string{"crwth"},
+			want:   []string{"crwth"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &config.Settings{CharacterSubstitutionMode: tt.mode, CharacterSubstitutions: subs, SeparatorCharacter: "-"}
+			svc, err := NewSubstitutionService(cfg, tt.rngSvc)
+			if err != nil {
+				t.Fatalf("NewSubstitutionService() error = %v", err)
+			}
+
+			input := slices.Clone(tt.input)
+			got, err := svc.Substitute(input)
+			if err != nil {
+				t.Fatalf("Substitute() error = %v", err)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Substitute() = %v, want %v", got, tt.want)
+			}
+
+			if !slices.Equal(input, tt.input) {
+				t.Errorf("Substitute() modified its input to %v", input)
+			}
+		})
+	}
+}
+
+func TestSubstituteRNGError(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Settings{
+		CharacterSubstitutionMode: option.CharacterSubstitutionModeRandom,
+		CharacterSubstitutions:    map[string]string{"a": "@"},
+		SeparatorCharacter:        "-",
+	}
+	svc, err := NewSubstitutionService(cfg, &mockErrRNGService{})
+	if err != nil {
+		t.Fatalf("NewSubstitutionService() error = %v", err)
+	}
+
+	if _, err := svc.Substitute([]string{"staple"}); !errors.Is(err, errMockRNGService) {
+		t.Errorf("Substitute() error = %v, want %v", err, errMockRNGService)
+	}
+}
+
+func TestGenerateCharacterSubstitutions(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.DefaultSettings()
+	cfg.CaseTransform = option.CaseTransformUpper
+	cfg.CharacterSubstitutions = map[string]string{"a": "4", "e": "3", "o": "0"}
+	cfg.SeparatorAlphabet = []string{"-", "."}
+
+	svc, err := NewGeneratorServiceWithRNG(cfg, NewSeededRNGService([]byte("substitution")))
+	if err != nil {
+		t.Fatalf("NewGeneratorServiceWithRNG() error = %v", err)
+	}
+
+	pws, err := svc.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	for _, pw := range pws {
+		// Substitutions apply to the upper case words
+		if strings.ContainsAny(pw, "AEOaeo") {
+			t.Errorf("Generate() password %q contains a substituted character", pw)
+		}
+	}
+}
+
+func TestCharacterSubstitutionsRejected(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		cfg  func(cfg *config.Settings)
+	}{
+		{
+			name: "Pattern",
+			cfg:  func(cfg *config.Settings) { cfg.Pattern = "w-w-w-dd" },
+		},
+		{
+			name: "Characters",
+			cfg: func(cfg *config.Settings) {
+				cfg.Generator = option.GeneratorCharacters
+				cfg.Length = 16
+				cfg.CharacterClasses = map[string]int{option.CharacterClassLower: 0}
+			},
+		},
+		{
+			name: "PIN",
+			cfg: func(cfg *config.Settings) {
+				cfg.Generator = option.GeneratorPIN
+				cfg.Length = 6
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := config.DefaultSettings()
+			cfg.CharacterSubstitutions = map[string]string{"a": "4", "e": "3"}
+			tt.cfg(cfg)
+
+			if _, err := NewGeneratorService(cfg); err == nil {
+				t.Error("NewGeneratorService() error = nil, want an error")
+			}
+
+			// The same settings are valid without character_substitutions
+			cfg.CharacterSubstitutions = nil
+			if _, err := NewGeneratorService(cfg); err != nil {
+				t.Errorf("NewGeneratorService() error = %v without character_substitutions", err)
+			}
+		})
+	}
+}
@@ -247,7 +247,8 @@ func (s *DefaultTransformerService) applyRandomCasing(slice []string) error {
 	}
 
 	for i, w := range slice {
-		r, err := s.rngSvc.Generate()
+		// A coin flip, so an RNG such as DiceRNGService spends as little as it can
+		r, err := s.rngSvc.GenerateWithMax(2)
 		if err != nil {
 			return fmt.Errorf("failed to generate random number for random case: %w", err)
 		}
@@ -279,13 +280,11 @@ func (s *DefaultTransformerService) ensureMixedCasing(slice []string) error {
 	}
 
 	if !hasUpper || !hasLower {
-		r, err := s.rngSvc.Generate()
+		randomIndex, err := s.rngSvc.GenerateWithMax(len(slice))
 		if err != nil {
 			return fmt.Errorf("failed to generate random number for random case adjustment: %w", err)
 		}
 
-		randomIndex := r % len(slice)
-
 		if !hasUpper {
 			slice[randomIndex] = strings.ToUpper(slice[randomIndex])
 		} else if !hasLower {
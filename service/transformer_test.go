@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"slices"
@@ -243,3 +244,27 @@ func TestTransformDoesNotMutateInput(t *testing.T) {
 		})
 	}
 }
+
+// An RNG whose full-width Generate fails, to check only bounded draws are used
+type mockBoundedRNGService struct {
+	mockRNGService
+}
+
+func (s *mockBoundedRNGService) Generate() (int, error) {
+	return 0, errors.New("unbounded draw")
+}
+
+func TestEnsureMixedCasingBoundedDraw(t *testing.T) {
+	t.Parallel()
+
+	svc := &DefaultTransformerService{rngSvc: &mockBoundedRNGService{}}
+
+	slice := []string{"correct", "horse", "battery"}
+	if err := svc.ensureMixedCasing(slice); err != nil {
+		t.Fatalf("ensureMixedCasing() error = %v", err)
+	}
+
+	if want := []string{"correct", "HORSE", "battery"}; !slices.Equal(slice, want) {
+		t.Errorf("ensureMixedCasing() = %v, want %v", slice, want)
+	}
+}
@@ -2,6 +2,12 @@ package service
 
 import (
 	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"slices"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/eljamo/libpass/v8/asset"
 	"github.com/eljamo/libpass/v8/config"
@@ -18,36 +24,284 @@ type WordListService interface {
 // Implements the interface WordListService, providing functionality to extract
 // words from a word list.
 type DefaultWordListService struct {
-	cfg      *config.Settings
-	rngSvc   RNGService
-	wordList []string
+	cfg       *config.Settings
+	rngSvc    RNGService
+	wordList  []string
+	groups    *wordGroups // Set when unique_words is, used to draw words without replacement
+	blocklist *blocklist  // Set when a blocklist is configured
+	longer    bool        // Whether the word list has kept words longer than word_length_max
 }
 
 const numWordMin = 2
 
 // Creates a new instance of DefaultWordListService. It requires configuration
-// and a random number generation service. It returns an error if the
-// configuration is invalid.
+// and a random number generation service. Words are read from the file set in
+// word_list_file if there is one, and from the embedded word list set in
+// word_list otherwise. It returns an error if the configuration is invalid.
 func NewWordListService(cfg *config.Settings, rngSvc RNGService) (*DefaultWordListService, error) {
+	if cfg.WordListFile != "" {
+		return NewWordListServiceFromFile(cfg, rngSvc, cfg.WordListFile)
+	}
+
+	source := fmt.Sprintf("%s (%s)", option.ConfigKeyWordList, cfg.WordList)
+
+	return newWordListService(cfg, rngSvc, source, func(minLen, maxLen int) ([]string, error) {
+		return asset.GetWordListView(cfg.WordList, minLen, maxLen)
+	})
+}
+
+// A WordListService which can report the entropy of the words it extracts.
+type reportingWordListService interface {
+	WordListService
+	WordEntropyReporter
+}
+
+// Creates the word list service for the configuration: a
+// PronounceableWordListService when generator is PRONOUNCEABLE, a
+// GrammaticalWordListService when generator is GRAMMATICAL, a
+// CompositeWordListService when word_list_weights or word_list_slots is set,
+// and a DefaultWordListService otherwise. It returns an error if the
+// configuration is invalid.
+func newConfiguredWordListService(cfg *config.Settings, rngSvc RNGService) (reportingWordListService, error) {
+	switch cfg.Generator {
+	case option.GeneratorPronounceable:
+		return NewPronounceableWordListService(cfg, rngSvc)
+	case option.GeneratorGrammatical:
+		return NewGrammaticalWordListService(cfg, rngSvc)
+	}
+
+	if len(cfg.WordListWeights) > 0 || len(cfg.WordListSlots) > 0 {
+		return NewCompositeWordListService(cfg, rngSvc)
+	}
+
+	return NewWordListService(cfg, rngSvc)
+}
+
+// Creates a new instance of DefaultWordListService which reads its words, one
+// per line, from an io.Reader instead of an embedded word list. The words are
+// filtered by length in the same way as an embedded word list. It returns an
+// error if the configuration is invalid or the reader cannot be read.
+func NewWordListServiceFromReader(cfg *config.Settings, rngSvc RNGService, r io.Reader) (*DefaultWordListService, error) {
+	return newWordListService(cfg, rngSvc, "word list reader", readOnce(func() ([]string, error) {
+		return asset.GetFilteredWordListFromReader(r, 1, math.MaxInt)
+	}))
+}
+
+// Creates a new instance of DefaultWordListService which reads its words, one
+// per line, from the file at the given path instead of an embedded word list.
+// It returns an error if the configuration is invalid or the file cannot be
+// read.
+func NewWordListServiceFromFile(cfg *config.Settings, rngSvc RNGService, filePath string) (*DefaultWordListService, error) {
+	source := fmt.Sprintf("%s (%s)", option.ConfigKeyWordListFile, filePath)
+
+	return newWordListService(cfg, rngSvc, source, readOnce(func() ([]string, error) {
+		return asset.GetFilteredWordListFromFile(filePath, 1, math.MaxInt)
+	}))
+}
+
+// Creates a new instance of DefaultWordListService which reads its words, one
+// per line, from the named file in the given file system instead of an
+// embedded word list. It returns an error if the configuration is invalid or
+// the file cannot be read.
+func NewWordListServiceFromFS(cfg *config.Settings, rngSvc RNGService, fsys fs.FS, name string) (*DefaultWordListService, error) {
+	return newWordListService(cfg, rngSvc, name, readOnce(func() ([]string, error) {
+		return asset.GetFilteredWordListFromFS(fsys, name, 1, math.MaxInt)
+	}))
+}
+
+// Returns a load function for newWordListService which reads every word with
+// read the first time it is called, and filters the words it read by length
+// after that, keeping them in the order they were read. A word list from a
+// reader can only be read once, and a file needn't be read more than once.
+func readOnce(read func() ([]string, error)) func(minLen int, maxLen int) ([]string, error) {
+	var all []string
+	var done bool
+
+	return func(minLen, maxLen int) ([]string, error) {
+		if !done {
+			wl, err := read()
+			if err != nil {
+				return nil, err
+			}
+			all, done = wl, true
+		}
+
+		return filterWordList(all, func(w string) bool {
+			n := utf8.RuneCountInString(w)
+			return n >= minLen && n <= maxLen
+		}), nil
+	}
+}
+
+// Validates the configuration and creates a new instance of
+// DefaultWordListService from the words returned by load, which filters a
+// word list by length. The source describes where the words come from in
+// error messages.
+func newWordListService(
+	cfg *config.Settings,
+	rngSvc RNGService,
+	source string,
+	load func(minLen int, maxLen int) ([]string, error),
+) (*DefaultWordListService, error) {
 	if cfg.NumWords < numWordMin {
 		return nil, fmt.Errorf("%s must be greater than or equal to %d", option.ConfigKeyNumWords, numWordMin)
 	}
 
-	wordList, err := getWordList(cfg.WordList, cfg.WordLengthMin, cfg.WordLengthMax)
+	bl, err := newBlocklist(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	keep, err := newWordFilter(cfg, bl)
+	if err != nil {
+		return nil, err
+	}
+
+	wordList, err := getWordList(source, cfg.WordLengthMin, cfg.WordLengthMax, load, keep)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups *wordGroups
+	if cfg.UniqueWords {
+		groups, err = newUniqueWordGroups(cfg, source, wordList)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	longer, err := hasLongerWords(cfg, load, keep)
 	if err != nil {
 		return nil, err
 	}
 
 	return &DefaultWordListService{
-		cfg,
-		rngSvc,
-		wordList,
+		cfg:       cfg,
+		rngSvc:    rngSvc,
+		wordList:  wordList,
+		groups:    groups,
+		blocklist: bl,
+		longer:    longer,
 	}, nil
 }
 
-// Creates a word list based on provided criteria. It returns an error if the
-// criteria are invalid or the word list cannot be created.
-func getWordList(wordList string, wordMinLength int, wordMaxLength int) ([]string, error) {
+// Reports whether load returns words longer than word_length_max which the
+// filter keeps, i.e. whether raising word_length_max adds words to the word
+// list.
+func hasLongerWords(cfg *config.Settings, load func(minLen int, maxLen int) ([]string, error), filter *wordFilter) (bool, error) {
+	if cfg.WordLengthMax == math.MaxInt {
+		return false, nil
+	}
+
+	wl, err := load(cfg.WordLengthMax+1, math.MaxInt)
+	if err != nil {
+		return false, err
+	}
+
+	if filter == nil {
+		return len(wl) > 0, nil
+	}
+
+	return slices.ContainsFunc(wl, filter.keep), nil
+}
+
+// Groups the words of a word list by the key they are compared on when
+// unique_words is set. It returns an error if the prefix length is negative
+// or there are fewer distinct words than num_words.
+func newUniqueWordGroups(cfg *config.Settings, source string, wordList []string) (*wordGroups, error) {
+	if cfg.UniqueWordPrefixLength < 0 {
+		return nil, fmt.Errorf("%s must be greater than or equal to 0", option.ConfigKeyUniqueWordPrefixLength)
+	}
+
+	groups := newWordGroups(wordList, cfg.UniqueWordPrefixLength)
+	if groups.count() < cfg.NumWords {
+		return nil, fmt.Errorf(
+			"not enough distinct words in %s for a %s of %d with %s set, found %d",
+			source,
+			option.ConfigKeyNumWords,
+			cfg.NumWords,
+			option.ConfigKeyUniqueWords,
+			groups.count(),
+		)
+	}
+
+	return groups, nil
+}
+
+// The words kept in a word list, and the settings which filter them, named in
+// errors.
+type wordFilter struct {
+	keep     func(word string) bool
+	settings []string
+}
+
+// Creates the filter of the words kept in the word list: those made of the
+// characters allowed by word_charset, which aren't blocked by the blocklist,
+// if there is one, and which have no ambiguous characters when avoid_ambiguous
+// is set. It returns nil if every word is kept, and an error if word_charset
+// is invalid.
+func newWordFilter(cfg *config.Settings, bl *blocklist) (*wordFilter, error) {
+	var keeps []func(string) bool
+	var settings []string
+	if cfg.WordCharset != "" && cfg.WordCharset != option.WordCharsetAny {
+		charset, err := asset.WordCharsetFilter(cfg.WordCharset, cfg.WordCharsetClass)
+		if err != nil {
+			return nil, err
+		}
+		keeps = append(keeps, charset)
+		settings = append(settings, option.ConfigKeyWordCharset)
+	}
+
+	if cfg.AvoidAmbiguous {
+		keeps = append(keeps, func(w string) bool { return !isAmbiguousWord(w, cfg.CaseTransform) })
+		settings = append(settings, option.ConfigKeyAvoidAmbiguous)
+	}
+
+	if bl != nil {
+		keeps = append(keeps, func(w string) bool { return !bl.blocks(w) })
+		settings = append(settings, "the blocklist")
+	}
+
+	if len(keeps) == 0 {
+		return nil, nil
+	}
+
+	return &wordFilter{
+		keep: func(w string) bool {
+			for _, keep := range keeps {
+				if !keep(w) {
+					return false
+				}
+			}
+
+			return true
+		},
+		settings: settings,
+	}, nil
+}
+
+// Returns the settings which filter the words, e.g. "word_charset and the
+// blocklist".
+func (f *wordFilter) describe() string {
+	if len(f.settings) == 1 {
+		return f.settings[0]
+	}
+
+	last := len(f.settings) - 1
+
+	return strings.Join(f.settings[:last], ", ") + " and " + f.settings[last]
+}
+
+// Creates a word list based on provided criteria, keeping only the words the
+// filter keeps if it isn't nil. It returns an error if the criteria are
+// invalid or the word list cannot be created.
+func getWordList(
+	source string,
+	wordMinLength int,
+	wordMaxLength int,
+	load func(minLen int, maxLen int) ([]string, error),
+	filter *wordFilter,
+) ([]string, error) {
 	if wordMaxLength < wordMinLength {
 		return nil, fmt.Errorf(
 			"%s (%d) must be greater than or equal to %s (%d)",
@@ -58,16 +312,21 @@ func getWordList(wordList string, wordMinLength int, wordMaxLength int) ([]strin
 		)
 	}
 
-	wl, err := asset.GetFilteredWordList(wordList, wordMinLength, wordMaxLength)
+	wl, err := load(wordMinLength, wordMaxLength)
 	if err != nil {
 		return nil, err
 	}
 
+	if filter != nil && len(wl) > 0 {
+		if wl = filterWordList(wl, filter.keep); len(wl) == 0 {
+			return nil, fmt.Errorf("no words left in %s once filtered by %s", source, filter.describe())
+		}
+	}
+
 	if len(wl) == 0 {
 		return nil, fmt.Errorf(
-			"no words found in %s (%s) with a %s of %d and %s of %d",
-			option.ConfigKeyWordList,
-			wordList,
+			"no words found in %s with a %s of %d and %s of %d",
+			source,
 			option.ConfigKeyWordLengthMin,
 			wordMinLength,
 			option.ConfigKeyWordLengthMax,
@@ -78,9 +337,32 @@ func getWordList(wordList string, wordMinLength int, wordMaxLength int) ([]strin
 	return wl, nil
 }
 
-// Creates a slice of words randomly extracted from a word list. It returns
-// an error if the slice cannot be created.
+// Returns a new slice of the words keep reports true for.
+func filterWordList(wordList []string, keep func(word string) bool) []string {
+	wl := make([]string, 0, len(wordList))
+	for _, w := range wordList {
+		if keep(w) {
+			wl = append(wl, w)
+		}
+	}
+
+	return wl
+}
+
+// Creates a slice of words randomly extracted from a word list. When
+// unique_words is set no two words in the slice share a group, and when a
+// blocklist is configured the words are drawn again while adjacent words form
+// a blocked word. It returns an error if the slice cannot be created.
 func (s *DefaultWordListService) GetWords() ([]string, error) {
+	return s.blocklist.drawWords(s.getWords)
+}
+
+// Creates a slice of words randomly extracted from a word list.
+func (s *DefaultWordListService) getWords() ([]string, error) {
+	if s.groups != nil {
+		return s.getUniqueWords()
+	}
+
 	wll := len(s.wordList)
 	wn, err := s.rngSvc.GenerateSliceWithMax(s.cfg.NumWords, wll)
 	if err != nil {
@@ -97,3 +379,127 @@ func (s *DefaultWordListService) GetWords() ([]string, error) {
 
 	return wl, nil
 }
+
+// WordList returns a copy of the filtered word list words are extracted from.
+func (s *DefaultWordListService) WordList() []string {
+	return slices.Clone(s.wordList)
+}
+
+func (s *DefaultWordListService) words() []string {
+	return s.wordList
+}
+
+func (s *DefaultWordListService) hasLongerWords() bool {
+	return s.longer
+}
+
+// WordEntropy returns the seen entropy in bits of the words in a password.
+func (s *DefaultWordListService) WordEntropy() float64 {
+	return wordListEntropy(s.cfg, s.wordList)
+}
+
+// Creates a slice of words randomly extracted from a word list without
+// replacement. Each word is drawn uniformly from the words whose group hasn't
+// been drawn yet, so once a word is drawn every word sharing its group is
+// excluded. It returns an error if the slice cannot be created.
+func (s *DefaultWordListService) getUniqueWords() ([]string, error) {
+	remaining := len(s.groups.order)
+	used := make([]int, 0, s.cfg.NumWords) // The groups drawn so far, in ascending order
+	wl := make([]string, s.cfg.NumWords)
+	for i := range wl {
+		n, err := s.rngSvc.GenerateWithMax(remaining)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate random word index number: %w", err)
+		}
+
+		if n < 0 || n >= remaining {
+			return nil, fmt.Errorf("number (%d) given out of range of remaining words (%d)", n, remaining)
+		}
+
+		// Skip over the groups already drawn to find the nth remaining word
+		pos := n
+		for _, g := range used {
+			if s.groups.starts[g] > pos {
+				break
+			}
+			pos += s.groups.size(g)
+		}
+
+		g := s.groups.groupAt(pos)
+		at, _ := slices.BinarySearch(used, g)
+		used = slices.Insert(used, at, g)
+		remaining -= s.groups.size(g)
+		wl[i] = s.wordList[s.groups.order[pos]]
+	}
+
+	return wl, nil
+}
+
+// wordGroups indexes a word list by the key words are compared on when
+// unique_words is set, so words sharing a key can be excluded together.
+type wordGroups struct {
+	order  []int // Indexes into the word list, sorted so the words of each group are adjacent
+	starts []int // The position in order each group starts at, followed by len(order)
+}
+
+// Groups the words of a word list by their uniqueWordKey.
+func newWordGroups(wordList []string, prefixLen int) *wordGroups {
+	keys := make([]string, len(wordList))
+	order := make([]int, len(wordList))
+	for i, w := range wordList {
+		keys[i] = uniqueWordKey(w, prefixLen)
+		order[i] = i
+	}
+
+	slices.SortStableFunc(order, func(a, b int) int {
+		return strings.Compare(keys[a], keys[b])
+	})
+
+	var starts []int
+	for i, idx := range order {
+		if i == 0 || keys[idx] != keys[order[i-1]] {
+			starts = append(starts, i)
+		}
+	}
+
+	return &wordGroups{order, append(starts, len(order))}
+}
+
+// Returns the number of groups.
+func (g *wordGroups) count() int {
+	return len(g.starts) - 1
+}
+
+// Returns the number of words in the given group.
+func (g *wordGroups) size(group int) int {
+	return g.starts[group+1] - g.starts[group]
+}
+
+// Returns the group of the word at the given position in order.
+func (g *wordGroups) groupAt(pos int) int {
+	i, found := slices.BinarySearch(g.starts, pos)
+	if found {
+		return i
+	}
+
+	return i - 1
+}
+
+// Returns the key a word is compared on when unique_words is set: the word in
+// lower case, so the case transformation can't make two words look the same,
+// cut to its first prefixLen runes when prefixLen is greater than 0.
+func uniqueWordKey(word string, prefixLen int) string {
+	key := strings.ToLower(word)
+	if prefixLen <= 0 {
+		return key
+	}
+
+	for i := range key {
+		if prefixLen == 0 {
+			return key[:i]
+		}
+		prefixLen--
+	}
+
+	return key
+}
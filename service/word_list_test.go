@@ -2,8 +2,14 @@ package service
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"testing/fstest"
 
+	"github.com/eljamo/libpass/v8/asset"
 	"github.com/eljamo/libpass/v8/config"
 	"github.com/eljamo/libpass/v8/config/option"
 )
@@ -53,7 +59,7 @@ func TestNewWordListService(t *testing.T) {
 func TestAllWordListsGetWords(t *testing.T) {
 	t.Parallel()
 
-	for _, wordList := range option.WordLists {
+	for _, wordList := range asset.WordLists.Names() {
 		t.Run(fmt.Sprintf("WordList_%s", wordList), func(t *testing.T) {
 			t.Parallel()
 			runWordListTest(t, wordList)
@@ -95,3 +101,362 @@ func runWordListTest(t *testing.T, wordList string) {
 		t.Fatalf("GetWords() expected error, got nil")
 	}
 }
+
+func TestWordListReturnsCopy(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Settings{NumWords: 2, WordList: option.WordListENSmall, WordLengthMin: 4, WordLengthMax: 4}
+	svc, err := NewWordListService(cfg, &mockRNGService{})
+	if err != nil {
+		t.Fatalf("NewWordListService() error = %v", err)
+	}
+
+	wl := svc.WordList()
+	if len(wl) == 0 {
+		t.Fatal("WordList() returned no words")
+	}
+
+	wl[0] = "changed"
+	if svc.WordList()[0] == "changed" {
+		t.Error("WordList() returned the service's backing slice, want a copy")
+	}
+}
+
+func TestNewWordListServiceFromSources(t *testing.T) {
+	t.Parallel()
+
+	const words = "apple\r\nbanana\r\n\r\ncherry\r\n"
+
+	filePath := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(filePath, []byte(words), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+
+	fsys := fstest.MapFS{"words.txt": &fstest.MapFile{Data: []byte(words)}}
+
+	newCfg := func(minLen, maxLen int) *config.Settings {
+		return &config.Settings{NumWords: 2, WordLengthMin: minLen, WordLengthMax: maxLen}
+	}
+
+	tests := []struct {
+		name       string
+		newSvc     func(cfg *config.Settings) (*DefaultWordListService, error)
+		minLen     int
+		maxLen     int
+		want       []string
+		wantLonger bool
+		wantErr    bool
+	}{
+		{
+			name: "Reader",
+			newSvc: func(cfg *config.Settings) (*DefaultWordListService, error) {
+				return NewWordListServiceFromReader(cfg, &mockRNGService{}, strings.NewReader(words))
+			},
+			minLen: 6,
+			maxLen: 6,
+			want:   []string{"banana", "cherry"},
+		},
+		{
+			name: "Reader with longer words",
+			newSvc: func(cfg *config.Settings) (*DefaultWordListService, error) {
+				return NewWordListServiceFromReader(cfg, &mockRNGService{}, strings.NewReader(words))
+			},
+			minLen:     1,
+			maxLen:     5,
+			want:       []string{"apple"},
+			wantLonger: true,
+		},
+		{
+			name: "File",
+			newSvc: func(cfg *config.Settings) (*DefaultWordListService, error) {
+				return NewWordListServiceFromFile(cfg, &mockRNGService{}, filePath)
+			},
+			minLen:     1,
+			maxLen:     5,
+			want:       []string{"apple"},
+			wantLonger: true,
+		},
+		{
+			name: "FS",
+			newSvc: func(cfg *config.Settings) (*DefaultWordListService, error) {
+				return NewWordListServiceFromFS(cfg, &mockRNGService{}, fsys, "words.txt")
+			},
+			minLen: 1,
+			maxLen: 10,
+			want:   []string{"apple", "banana", "cherry"},
+		},
+		{
+			name: "word_list_file setting",
+			newSvc: func(cfg *config.Settings) (*DefaultWordListService, error) {
+				cfg.WordList = option.WordListEN
+				cfg.WordListFile = filePath
+
+				return NewWordListService(cfg, &mockRNGService{})
+			},
+			minLen: 1,
+			maxLen: 10,
+			want:   []string{"apple", "banana", "cherry"},
+		},
+		{
+			name: "Missing file",
+			newSvc: func(cfg *config.Settings) (*DefaultWordListService, error) {
+				return NewWordListServiceFromFile(cfg, &mockRNGService{}, filepath.Join(t.TempDir(), "missing.txt"))
+			},
+			minLen:  1,
+			maxLen:  10,
+			wantErr: true,
+		},
+		{
+			name: "No words of the given length",
+			newSvc: func(cfg *config.Settings) (*DefaultWordListService, error) {
+				return NewWordListServiceFromReader(cfg, &mockRNGService{}, strings.NewReader(words))
+			},
+			minLen:  10,
+			maxLen:  12,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc, err := tt.newSvc(newCfg(tt.minLen, tt.maxLen))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got := svc.WordList(); !slices.Equal(got, tt.want) {
+				t.Errorf("%s: WordList() = %v, want %v", tt.name, got, tt.want)
+			}
+
+			if got := svc.hasLongerWords(); got != tt.wantLonger {
+				t.Errorf("%s: hasLongerWords() = %v, want %v", tt.name, got, tt.wantLonger)
+			}
+		})
+	}
+}
+
+func TestGetUniqueWords(t *testing.T) {
+	t.Parallel()
+
+	const words = "apple\nApple\napricot\nbanana\nblueberry\ncherry\n"
+
+	tests := []struct {
+		name      string
+		numWords  int
+		prefixLen int
+		rngSvc    RNGService
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name:     "Case-insensitive duplicates are excluded",
+			numWords: 3,
+			rngSvc:   &mockRNGService{},
+			want:     []string{"Apple", "banana", "blueberry"},
+		},
+		{
+			name:      "Words sharing a prefix are excluded",
+			numWords:  3,
+			prefixLen: 2,
+			rngSvc:    &mockRNGService{},
+			want:      []string{"Apple", "blueberry", "cherry"},
+		},
+		{
+			name:     "RNG error",
+			numWords: 3,
+			rngSvc:   &mockErrRNGService{},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &config.Settings{
+				NumWords: tt.numWords, WordLengthMin: 1, WordLengthMax: 10,
+				UniqueWords: true, UniqueWordPrefixLength: tt.prefixLen,
+			}
+
+			svc, err := NewWordListServiceFromReader(cfg, tt.rngSvc, strings.NewReader(words))
+			if err != nil {
+				t.Fatalf("NewWordListServiceFromReader() error = %v", err)
+			}
+
+			got, err := svc.GetWords()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetWords() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !slices.Equal(got, tt.want) {
+				t.Errorf("GetWords() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetUniqueWordsNeverRepeats(t *testing.T) {
+	t.Parallel()
+
+	// Four groups with a prefix length of 2: ap, ba, bl and ch
+	const words = "apple\nApple\napricot\nbanana\nblueberry\ncherry\n"
+
+	cfg := &config.Settings{
+		NumWords: 4, WordLengthMin: 1, WordLengthMax: 10, UniqueWords: true, UniqueWordPrefixLength: 2,
+	}
+
+	svc, err := NewWordListServiceFromReader(cfg, NewSeededRNGService([]byte("unique")), strings.NewReader(words))
+	if err != nil {
+		t.Fatalf("NewWordListServiceFromReader() error = %v", err)
+	}
+
+	for range 200 {
+		got, err := svc.GetWords()
+		if err != nil {
+			t.Fatalf("GetWords() error = %v", err)
+		}
+
+		seen := make(map[string]bool, len(got))
+		for _, w := range got {
+			key := uniqueWordKey(w, cfg.UniqueWordPrefixLength)
+			if seen[key] {
+				t.Fatalf("GetWords() = %v, want no two words sharing a prefix", got)
+			}
+			seen[key] = true
+		}
+	}
+}
+
+func TestNewWordListServiceUniqueWordsErrors(t *testing.T) {
+	t.Parallel()
+
+	const words = "apple\nApple\napricot\nbanana\nblueberry\ncherry\n"
+
+	tests := []struct {
+		name      string
+		numWords  int
+		prefixLen int
+	}{
+		{name: "More words than distinct words", numWords: 6},
+		{name: "More words than distinct prefixes", numWords: 4, prefixLen: 1},
+		{name: "Negative prefix length", numWords: 2, prefixLen: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &config.Settings{
+				NumWords: tt.numWords, WordLengthMin: 1, WordLengthMax: 10,
+				UniqueWords: true, UniqueWordPrefixLength: tt.prefixLen,
+			}
+
+			if _, err := NewWordListServiceFromReader(cfg, &mockRNGService{}, strings.NewReader(words)); err == nil {
+				t.Errorf("%s: NewWordListServiceFromReader() error = nil, want an error", tt.name)
+			}
+		})
+	}
+}
+
+func TestUniqueWordKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		word      string
+		prefixLen int
+		want      string
+	}{
+		{word: "Apple", prefixLen: 0, want: "apple"},
+		{word: "Apple", prefixLen: 3, want: "app"},
+		{word: "Apple", prefixLen: 10, want: "apple"},
+		{word: "Éclair", prefixLen: 2, want: "éc"},
+	}
+
+	for _, tt := range tests {
+		if got := uniqueWordKey(tt.word, tt.prefixLen); got != tt.want {
+			t.Errorf("uniqueWordKey(%q, %d) = %q, want %q", tt.word, tt.prefixLen, got, tt.want)
+		}
+	}
+}
+
+func TestNewWordListServiceWordCharset(t *testing.T) {
+	t.Parallel()
+
+	const words = "43m\napple\nwell-known\n6p62\nbanana\n"
+
+	tests := []struct {
+		name    string
+		charset string
+		want    []string
+		wantErr bool
+	}{
+		{name: "Any", charset: option.WordCharsetAny, want: []string{"43m", "apple", "well-known", "6p62", "banana"}},
+		{name: "ASCII letters", charset: option.WordCharsetASCIILetters, want: []string{"apple", "banana"}},
+		{name: "Letters and hyphens", charset: option.WordCharsetLettersHyphen, want: []string{"apple", "well-known", "banana"}},
+		{name: "Invalid", charset: "DIGITS", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &config.Settings{NumWords: 2, WordLengthMin: 1, WordLengthMax: 10, WordCharset: tt.charset}
+			svc, err := NewWordListServiceFromReader(cfg, &mockRNGService{}, strings.NewReader(words))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewWordListServiceFromReader() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !slices.Equal(svc.WordList(), tt.want) {
+				t.Errorf("WordList() = %v, want %v", svc.WordList(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNewWordListServiceFilteredOut(t *testing.T) {
+	t.Parallel()
+
+	const words = "43m\n6p62\n"
+
+	tests := []struct {
+		name string
+		cfg  *config.Settings
+		want string
+	}{
+		{
+			name: "Word charset",
+			cfg:  &config.Settings{WordCharset: option.WordCharsetLetters},
+			want: "once filtered by word_charset",
+		},
+		{
+			name: "Word charset and the blocklist",
+			cfg:  &config.Settings{WordCharset: option.WordCharsetLetters, Blocklist: []string{"43m"}},
+			want: "once filtered by word_charset and the blocklist",
+		},
+		{
+			name: "Every filter",
+			cfg:  &config.Settings{WordCharset: option.WordCharsetLetters, AvoidAmbiguous: true, Blocklist: []string{"43m"}},
+			want: "once filtered by word_charset, avoid_ambiguous and the blocklist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.cfg.NumWords = 2
+			tt.cfg.WordLengthMin = 1
+			tt.cfg.WordLengthMax = 10
+			_, err := NewWordListServiceFromReader(tt.cfg, &mockRNGService{}, strings.NewReader(words))
+			if err == nil || !strings.HasSuffix(err.Error(), tt.want) {
+				t.Errorf("NewWordListServiceFromReader() error = %v, want it to end with %q", err, tt.want)
+			}
+		})
+	}
+}